@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gaokao-data-analysis/config"
+)
+
+const configUsage = `Usage: gaokao config print [flags]
+
+  print  输出合并配置文件、.env、环境变量和命令行参数后生效的配置，密码、密钥等敏感配置不输出原值
+
+Flags:
+`
+
+// RunConfig 执行 config 子命令
+func RunConfig(args []string) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	format := fs.String("format", config.PrintFormatYAML, "输出格式: yaml, env")
+	configFlags := config.RegisterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), configUsage)
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "print" {
+		fs.Usage()
+		return fmt.Errorf("unknown config action")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := configFlags.Load()
+	if err != nil {
+		return err
+	}
+	return cfg.Print(os.Stdout, *format)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gaokao-data-analysis/config"
+	"gaokao-data-analysis/etl"
+)
+
+const etlUsage = `Usage: gaokao etl load -file <招生计划.xlsx|csv> [flags]
+
+  load    校验招生计划表并导入 gaokao2025（ADMISSION_BACKEND 决定写入 ClickHouse 或关系型数据库）
+
+Flags:
+`
+
+// RunETL 执行 etl 子命令
+func RunETL(args []string) error {
+	fs := flag.NewFlagSet("etl", flag.ContinueOnError)
+	file := fs.String("file", "", "招生计划表路径，支持 .xlsx 和 .csv")
+	headerRows := fs.Int("header-rows", 0, "表头行数，默认 xlsx 为 3、csv 为 1")
+	replace := fs.Bool("replace", false, "以导入的数据替换 gaokao2025 表中的全部数据，否则追加；写入失败时原有数据保持不变")
+	dryRun := fs.Bool("dry-run", false, "只校验，不写入数据库")
+	batchSize := fs.Int("batch-size", 5000, "每批写入的行数")
+	report := fs.String("report", "", "被拒绝行的报告输出路径（CSV），默认输出到终端")
+	configFlags := config.RegisterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), etlUsage)
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "load" {
+		fs.Usage()
+		return fmt.Errorf("unknown etl action")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *file == "" {
+		fs.Usage()
+		return fmt.Errorf("-file is required")
+	}
+	if *headerRows == 0 {
+		*headerRows = 1
+		if strings.EqualFold(filepath.Ext(*file), ".xlsx") {
+			*headerRows = 3
+		}
+	}
+
+	table, err := etl.ReadTable(*file, *headerRows)
+	if err != nil {
+		return err
+	}
+
+	// dry-run 只校验文件，不连接数据库
+	var sink etl.Sink
+	if !*dryRun {
+		cfg, err := configFlags.Load()
+		if err != nil {
+			return err
+		}
+		if err := config.InitDatabase(cfg); err != nil {
+			return err
+		}
+		if sink, err = etl.GetSink(); err != nil {
+			return err
+		}
+	}
+
+	result, err := etl.Load(context.Background(), table, sink, etl.LoadOptions{
+		Replace:   *replace,
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+	})
+	if result != nil {
+		fmt.Printf("total: %d, loaded: %d, history: %d, rejected: %d\n", result.Total, result.Loaded, result.History, len(result.Rejected))
+		if reportErr := writeRejectedReport(*report, result.Rejected); reportErr != nil {
+			return reportErr
+		}
+	}
+	return err
+}
+
+// writeRejectedReport 输出被拒绝的行，path 为空时输出到终端
+func writeRejectedReport(path string, rejected []etl.RowError) error {
+	if len(rejected) == 0 {
+		return nil
+	}
+
+	if path == "" {
+		for _, rowErr := range rejected {
+			slog.Warn("拒绝导入", "line", rowErr.Line, "field", rowErr.Field, "error", rowErr.Msg)
+		}
+		return nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"line", "field", "error"}); err != nil {
+		return err
+	}
+	for _, rowErr := range rejected {
+		if err := writer.Write([]string{strconv.Itoa(rowErr.Line), rowErr.Field, rowErr.Msg}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	fmt.Printf("rejected rows written to %s\n", path)
+	return nil
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"gaokao-data-analysis/config"
+	"gaokao-data-analysis/migrations"
+)
+
+const migrateUsage = `Usage: gaokao migrate <up|down|status> [flags]
+
+  up      执行未执行的迁移
+  down    回滚最近执行的迁移
+  status  查看迁移状态
+
+Flags:
+`
+
+// RunMigrate 执行 migrate 子命令
+func RunMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	target := fs.String("target", migrations.TargetAll, "迁移目标: all, relational, clickhouse")
+	to := fs.Int("to", 0, "up: 执行到指定版本，0 表示最新版本")
+	steps := fs.Int("steps", 1, "down: 回滚的迁移数量")
+	configFlags := config.RegisterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), migrateUsage)
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		fs.Usage()
+		return fmt.Errorf("missing migrate action")
+	}
+	action := args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch action {
+	case "up", "down", "status":
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown migrate action: %s", action)
+	}
+	if action == "down" && *steps <= 0 {
+		return fmt.Errorf("-steps must be positive")
+	}
+
+	cfg, err := configFlags.Load()
+	if err != nil {
+		return err
+	}
+	if err := config.InitDatabase(cfg); err != nil {
+		return err
+	}
+
+	migrators, err := migrations.ForTarget(*target)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, migrator := range migrators {
+		switch action {
+		case "up":
+			applied, err := migrator.Up(ctx, *to)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s: applied %d migration(s)\n", migrator.Name(), applied)
+		case "down":
+			rolledBack, err := migrator.Down(ctx, *steps)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s: rolled back %d migration(s)\n", migrator.Name(), rolledBack)
+		case "status":
+			if err := printMigrationStatus(ctx, migrator); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// printMigrationStatus 以表格形式输出迁移状态
+func printMigrationStatus(ctx context.Context, migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s:\n", migrator.Name())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "  %d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
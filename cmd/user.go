@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"gaokao-data-analysis/config"
+	"gaokao-data-analysis/models"
+)
+
+const userUsage = `Usage: gaokao user set-role -account <phone|email> -role <role> [flags]
+
+  set-role  修改账号角色，用于创建首个管理员；之后可由管理员通过 PUT /api/admin/users/role 修改
+
+Flags:
+`
+
+// RunUser 执行 user 子命令
+func RunUser(args []string) error {
+	fs := flag.NewFlagSet("user", flag.ContinueOnError)
+	account := fs.String("account", "", "账号手机号或邮箱")
+	role := fs.String("role", "", "角色: student, parent, counselor, admin")
+	configFlags := config.RegisterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), userUsage)
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "set-role" {
+		fs.Usage()
+		return fmt.Errorf("unknown user action")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *account == "" || *role == "" {
+		fs.Usage()
+		return fmt.Errorf("-account and -role are required")
+	}
+
+	cfg, err := configFlags.Load()
+	if err != nil {
+		return err
+	}
+	if err := config.InitDatabase(cfg); err != nil {
+		return err
+	}
+
+	user, err := models.SetUserRole(context.Background(), *account, *role)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: role set to %s\n", user.ID, user.Role)
+	return nil
+}
@@ -1,45 +1,229 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"gaokao-data-analysis/database"
 	"gaokao-data-analysis/logs"
+	"gaokao-data-analysis/migrations"
 	"gaokao-data-analysis/models"
-	"os"
-
-	"github.com/joho/godotenv"
+	"gaokao-data-analysis/ratelimit"
+	"gaokao-data-analysis/telemetry"
+	"gaokao-data-analysis/utils"
+	"log/slog"
+	"net"
+	"time"
 )
 
-// loadDotEnvConfig 从 .env 文件加载环境变量
-func loadDotEnvConfig() error {
-	// 尝试加载 .env 文件，如果存在的话
-	err := godotenv.Load()
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("加载 .env 文件错误: %w", err)
+// Config 应用配置，由 Load 从配置文件、.env、环境变量和命令行参数合并得到
+// yaml 标签为配置文件中的键，env 标签为对应的环境变量
+type Config struct {
+	Server     ServerConfig              `yaml:"server"`
+	Log        logs.Config               `yaml:"log"`
+	Database   database.Config           `yaml:"database"`
+	ClickHouse database.ClickHouseConfig `yaml:"clickhouse"`
+	Auth       AuthConfig                `yaml:"auth"`
+	Profile    ProfileConfig             `yaml:"profile"`
+	RateLimit  ratelimit.Config          `yaml:"rate_limit"`
+	CORS       CORSConfig                `yaml:"cors"`
+	Metrics    MetricsConfig             `yaml:"metrics"`
+	Telemetry  telemetry.Config          `yaml:"telemetry"`
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	Port int `yaml:"port" env:"PORT"`
+	// Mode gin 运行模式: debug, release, test
+	Mode string `yaml:"mode" env:"GIN_MODE"`
+
+	// 读取请求头、读取整个请求、写入响应和空闲连接的超时时间，为 0 时不限制
+	// 写入超时需大于 ClickHouse 查询的最长执行时间，否则志愿推荐请求可能在返回前被断开
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT_SECONDS" unit:"s"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT_SECONDS" unit:"s"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT_SECONDS" unit:"s"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT_SECONDS" unit:"s"`
+
+	// ShutdownDelay 收到退出信号后，就绪检查返回 503 并继续处理请求的时间，便于负载均衡摘除实例
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY_SECONDS" unit:"s"`
+	// ShutdownTimeout 停止接受新连接后等待进行中请求完成的最长时间，超时后强制关闭
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT_SECONDS" unit:"s"`
+
+	// 证书和私钥文件路径，都配置时使用 HTTPS
+	TLSCertFile string `yaml:"tls_cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile  string `yaml:"tls_key_file" env:"TLS_KEY_FILE"`
+
+	// TrustedProxies 可信反向代理的 IP 或 CIDR，只有来自这些地址的 X-Forwarded-For、X-Real-IP 才用于确定客户端 IP
+	// 为空时不信任任何代理，客户端 IP 取连接的对端地址，避免伪造请求头绕过按 IP 限流
+	TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+	// TrustedPlatform 由云平台设置的客户端 IP 请求头，如 CF-Connecting-IP，配置后优先于 TrustedProxies
+	TrustedPlatform string `yaml:"trusted_platform" env:"SERVER_TRUSTED_PLATFORM"`
+}
+
+// TLSEnabled 是否配置了证书
+func (s *ServerConfig) TLSEnabled() bool {
+	return s.TLSCertFile != "" && s.TLSKeyFile != ""
+}
+
+// AuthConfig 登录令牌配置
+type AuthConfig struct {
+	// JWTSecret 令牌签名密钥，为空时每次启动随机生成
+	JWTSecret string `yaml:"jwt_secret" env:"AUTH_JWT_SECRET" secret:"true"`
+	// TokenTTL 令牌有效期
+	TokenTTL time.Duration `yaml:"token_ttl" env:"AUTH_TOKEN_TTL_HOURS" unit:"h"`
+}
+
+// ProfileConfig 档案校验配置
+type ProfileConfig struct {
+	// ScoreTolerance 分数与位次换算结果允许的最大分差
+	ScoreTolerance int `yaml:"score_tolerance" env:"PROFILE_SCORE_TOLERANCE"`
+}
+
+// MetricsConfig Prometheus 指标配置
+type MetricsConfig struct {
+	// Enabled 是否暴露 /metrics
+	Enabled bool `yaml:"enabled" env:"METRICS_ENABLED"`
+}
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			Mode:              "release",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      90 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownDelay:     5 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Log:        logs.DefaultConfig(),
+		Database:   database.DefaultConfig(),
+		ClickHouse: database.DefaultClickHouseConfig(),
+		Auth:       AuthConfig{TokenTTL: 72 * time.Hour},
+		Profile:    ProfileConfig{ScoreTolerance: 5},
+		RateLimit:  ratelimit.DefaultConfig(),
+		CORS:       DefaultCORSConfig(),
+		Metrics:    MetricsConfig{Enabled: true},
+		Telemetry:  telemetry.DefaultConfig(),
 	}
-	return nil
 }
 
-// 初始化 config
-func InitConfig() error {
-	// 加载配置
-	if err := loadDotEnvConfig(); err != nil {
-		return fmt.Errorf("加载配置失败: %w", err)
+// normalize 补全依赖其他配置项的默认值
+func (c *Config) normalize() {
+	c.Database.Normalize()
+}
+
+// Validate 校验所有配置，返回全部问题
+// ClickHouse 配置只在录取数据使用 ClickHouse 时校验
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT is invalid: %d", c.Server.Port))
+	}
+	switch c.Server.Mode {
+	case "debug", "release", "test":
+	default:
+		errs = append(errs, fmt.Errorf("GIN_MODE 无效: %s，可选值为 debug, release, test", c.Server.Mode))
+	}
+	if c.Server.ReadHeaderTimeout < 0 || c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 {
+		errs = append(errs, errors.New("SERVER_*_TIMEOUT_SECONDS must not be negative"))
+	}
+	if c.Server.ShutdownDelay < 0 || c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SERVER_SHUTDOWN_DELAY_SECONDS must not be negative and SERVER_SHUTDOWN_TIMEOUT_SECONDS must be positive"))
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("SERVER_TRUSTED_PROXIES 无效: %s，需为 IP 或 CIDR", proxy))
+			}
+		}
+	}
+	if c.Auth.TokenTTL <= 0 {
+		errs = append(errs, errors.New("AUTH_TOKEN_TTL_HOURS must be positive"))
+	}
+	if c.Profile.ScoreTolerance < 0 {
+		errs = append(errs, fmt.Errorf("PROFILE_SCORE_TOLERANCE must not be negative: %d", c.Profile.ScoreTolerance))
+	}
+
+	errs = append(errs, c.Log.Validate(), c.Database.Validate())
+	if c.Database.AdmissionBackend == database.AdmissionBackendClickHouse {
+		errs = append(errs, c.ClickHouse.Validate())
+	}
+	errs = append(errs, c.RateLimit.Validate(), c.CORS.Validate(), c.Telemetry.Validate())
+	return errors.Join(errs...)
+}
+
+// InitDatabase 初始化日志系统和数据库连接，不执行迁移
+func InitDatabase(cfg *Config) error {
+	// 初始化日志系统，需在数据库之前完成，GORM 日志使用此时的默认记录器
+	if err := logs.Setup(&cfg.Log); err != nil {
+		return fmt.Errorf("初始化日志系统失败: %w", err)
 	}
 
 	// 初始化数据库
-	if err := database.InitDatabase(); err != nil {
+	if err := database.InitDatabase(&cfg.Database, &cfg.ClickHouse); err != nil {
 		return fmt.Errorf("初始化数据库失败: %w", err)
 	}
+	return nil
+}
 
-	// 自动迁移数据库模型
-	if err := database.GetDB().AutoMigrate(&models.UserProfile{}); err != nil {
-		return fmt.Errorf("自动迁移数据库模型失败: %w", err)
+// InitConfig 初始化日志、数据库和各模块的配置，并执行或检查数据库迁移
+func InitConfig(cfg *Config) error {
+	if err := InitDatabase(cfg); err != nil {
+		return err
 	}
+	utils.SetTokenConfig(cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
+	models.SetScoreTolerance(cfg.Profile.ScoreTolerance)
 
-	// 初始化日志系统
-	if err := logs.InitLogger(); err != nil {
-		return fmt.Errorf("初始化日志系统失败: %w", err)
+	// 执行或检查数据库迁移
+	if err := runStartupMigrations(cfg.Database.MigrateOnStart); err != nil {
+		return fmt.Errorf("数据库迁移失败: %w", err)
+	}
+	return nil
+}
+
+// runStartupMigrations migrateOnStart 为 true 时执行未执行的迁移，否则只提示待执行的迁移
+// ClickHouse 未连接时跳过其迁移，可稍后通过 migrate 命令执行
+func runStartupMigrations(migrateOnStart bool) error {
+	ctx := context.Background()
+
+	targets := []string{migrations.TargetRelational}
+	if database.GetAdmissionBackend() == database.AdmissionBackendClickHouse {
+		if !database.IsClickHouseReady() {
+			slog.Warn("ClickHouse未连接，跳过ClickHouse迁移")
+		} else {
+			targets = append(targets, migrations.TargetClickHouse)
+		}
+	}
+
+	for _, target := range targets {
+		migrators, err := migrations.ForTarget(target)
+		if err != nil {
+			return err
+		}
+		for _, migrator := range migrators {
+			if migrateOnStart {
+				applied, err := migrator.Up(ctx, 0)
+				if err != nil {
+					return err
+				}
+				slog.Info("数据库迁移完成", "target", migrator.Name(), "applied", applied)
+				continue
+			}
+
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if pending > 0 {
+				slog.Warn("存在未执行的数据库迁移，请执行 migrate up", "target", migrator.Name(), "pending", pending)
+			}
+		}
 	}
 	return nil
 }
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"gaokao-data-analysis/database"
+)
+
+// validConfig 返回可通过校验的最小配置，使用 SQLite 且不依赖 ClickHouse
+func validConfig() *Config {
+	cfg := Default()
+	cfg.Database.Type = "sqlite"
+	cfg.Database.AdmissionBackend = database.AdmissionBackendSQL
+	cfg.normalize()
+	return cfg
+}
+
+func TestValidateTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		wantErr bool
+	}{
+		{name: "不信任任何代理", proxies: nil},
+		{name: "IPv4 地址", proxies: []string{"10.0.0.1"}},
+		{name: "IPv6 地址", proxies: []string{"::1"}},
+		{name: "CIDR", proxies: []string{"10.0.0.0/8", "fd00::/8"}},
+		{name: "主机名", proxies: []string{"proxy.internal"}, wantErr: true},
+		{name: "无效的 CIDR", proxies: []string{"10.0.0.0/33"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Server.TrustedProxies = tt.proxies
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
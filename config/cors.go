@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// 跨域默认允许和暴露的请求头，与路由中使用的请求头、限流响应头一致
+const (
+	defaultCORSAllowedHeaders = "Origin,Content-Type,Accept,Authorization,X-Request-ID"
+	defaultCORSExposedHeaders = "X-Request-ID,Retry-After,X-RateLimit-Limit,X-RateLimit-Remaining"
+)
+
+// CORSConfig 跨域配置，AllowedOrigins 为空时不启用跨域
+type CORSConfig struct {
+	// AllowedOrigins 允许的来源，* 表示任意来源，支持 https://*.example.com 形式的子域名通配
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	// AllowedMethods 预检请求允许的方法
+	AllowedMethods []string `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	// AllowedHeaders 预检请求允许的请求头
+	AllowedHeaders []string `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	// ExposedHeaders 浏览器可读取的响应头
+	ExposedHeaders []string `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	// AllowCredentials 是否允许携带 Cookie 等凭证
+	AllowCredentials bool `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	// MaxAge 预检结果缓存时间
+	MaxAge time.Duration `yaml:"max_age" env:"CORS_MAX_AGE_SECONDS" unit:"s"`
+}
+
+// DefaultCORSConfig 返回默认跨域配置
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders: splitList(defaultCORSAllowedHeaders),
+		ExposedHeaders: splitList(defaultCORSExposedHeaders),
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// Enabled 是否配置了允许的来源
+func (cfg *CORSConfig) Enabled() bool {
+	return len(cfg.AllowedOrigins) > 0
+}
+
+// Validate 校验跨域配置
+func (cfg *CORSConfig) Validate() error {
+	if cfg.MaxAge < 0 {
+		return errors.New("CORS_MAX_AGE_SECONDS must not be negative")
+	}
+	return nil
+}
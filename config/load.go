@@ -0,0 +1,332 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// 配置项使用的结构体标签
+//
+//	yaml      配置文件中的键名，嵌套结构体的键名以 . 连接后作为命令行参数名，如 -database.host
+//	env       环境变量名
+//	envPrefix 嵌套结构体中环境变量的前缀，如连接池配置的 DB_
+//	unit      time.Duration 字段在配置文件、环境变量和命令行参数中的数值单位: ms, s, h, d；也可以写 1m30s 形式
+//	secret    为 true 时 config print 输出 ******
+
+// configFileEnv 指定配置文件路径的环境变量，-config 参数优先
+const configFileEnv = "CONFIG_FILE"
+
+// field 一个配置项
+type field struct {
+	// path 配置文件键路径，如 database.pool.max_open_conns
+	path   string
+	env    string
+	unit   string
+	secret bool
+	value  reflect.Value
+}
+
+// fields 按声明顺序返回配置中的所有配置项
+func fields(cfg *Config) []field {
+	return collectFields(reflect.ValueOf(cfg).Elem(), "", "")
+}
+
+func collectFields(v reflect.Value, pathPrefix, envPrefix string) []field {
+	var result []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if !sf.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if isSection(fv) {
+			result = append(result, collectFields(fv, pathPrefix+name+".", envPrefix+sf.Tag.Get("envPrefix"))...)
+			continue
+		}
+
+		f := field{
+			path:   pathPrefix + name,
+			unit:   sf.Tag.Get("unit"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  fv,
+		}
+		if env := sf.Tag.Get("env"); env != "" {
+			f.env = envPrefix + env
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// isSection 判断字段是否为嵌套的配置节，实现 TextUnmarshaler 的结构体（如 slog.Level）作为单个值处理
+func isSection(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return !ok
+}
+
+// Load 解析命令行参数并加载配置，args 为子命令之后的命令行参数
+func Load(name string, args []string) (*Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return flags.Load()
+}
+
+// Flags 命令行中与配置相关的参数
+type Flags struct {
+	file      string
+	overrides []flagOverride
+}
+
+// flagOverride 命令行参数设置的值，在环境变量之后应用
+type flagOverride struct {
+	path  string
+	value string
+}
+
+// RegisterFlags 在 fs 上注册 -config 参数，并为每个配置项注册以键路径命名的参数，如 -server.port 8080
+// 子命令可在自己的参数之外注册这些参数，解析后调用 Load
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{}
+	fs.StringVar(&flags.file, "config", "", "配置文件路径（.yaml/.yml/.toml），也可通过 CONFIG_FILE 环境变量指定")
+
+	for _, f := range fields(Default()) {
+		usage := "默认 " + f.format()
+		if f.env != "" {
+			usage = "环境变量 " + f.env + "，" + usage
+		}
+		if f.unit != "" {
+			usage += "，单位 " + f.unit
+		}
+		path := f.path
+		fs.Func(path, usage, func(value string) error {
+			flags.overrides = append(flags.overrides, flagOverride{path: path, value: value})
+			return nil
+		})
+	}
+	return flags
+}
+
+// Load 按优先级从低到高合并配置：默认值、配置文件（YAML/TOML）、.env 文件、环境变量、命令行参数，并校验结果
+// 返回的错误包含所有无法解析或校验失败的配置项
+func (flags *Flags) Load() (*Config, error) {
+	// .env 不覆盖已存在的环境变量，因此环境变量优先于 .env
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("加载 .env 文件错误: %w", err)
+	}
+
+	path := flags.file
+	if path == "" {
+		path = os.Getenv(configFileEnv)
+	}
+
+	cfg := Default()
+	byPath := make(map[string]field)
+	for _, f := range fields(cfg) {
+		byPath[f.path] = f
+	}
+
+	var errs []error
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			f, ok := byPath[key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("配置文件 %s: 未知的配置项 %s", path, key))
+				continue
+			}
+			if err := f.set(values[key]); err != nil {
+				errs = append(errs, fmt.Errorf("配置文件 %s: %s 无效: %w", path, key, err))
+			}
+		}
+	}
+
+	for _, f := range fields(cfg) {
+		if f.env == "" {
+			continue
+		}
+		if value := os.Getenv(f.env); value != "" {
+			if err := f.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("环境变量 %s 无效: %w", f.env, err))
+			}
+		}
+	}
+
+	for _, override := range flags.overrides {
+		if err := byPath[override.path].set(override.value); err != nil {
+			errs = append(errs, fmt.Errorf("命令行参数 -%s 无效: %w", override.path, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("配置无效: %w", err)
+	}
+	return cfg, nil
+}
+
+// readFile 读取配置文件并展开为 键路径 → 值 的映射
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	var doc map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("不支持的配置文件格式: %s，可选 .yaml, .yml, .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten(doc, "", values)
+	return values, nil
+}
+
+// flatten 将嵌套的配置展开为以 . 连接的键路径，列表以逗号连接
+func flatten(doc map[string]interface{}, prefix string, values map[string]string) {
+	for key, value := range doc {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(v, prefix+key+".", values)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[prefix+key] = strings.Join(items, ",")
+		case nil:
+			values[prefix+key] = ""
+		default:
+			values[prefix+key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// durationUnits unit 标签对应的时长
+var durationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+}
+
+// set 将字符串形式的值写入配置项
+func (f field) set(value string) error {
+	value = strings.TrimSpace(value)
+	if u, ok := f.value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	switch f.value.Interface().(type) {
+	case time.Duration:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && f.unit != "" {
+			f.value.SetInt(n * int64(durationUnits[f.unit]))
+			return nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q 不是有效的时长", value)
+		}
+		f.value.SetInt(int64(d))
+		return nil
+	case []string:
+		f.value.Set(reflect.ValueOf(splitList(value)))
+		return nil
+	}
+
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q 不是有效的布尔值", value)
+		}
+		f.value.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q 不是有效的整数", value)
+		}
+		f.value.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q 不是有效的数字", value)
+		}
+		f.value.SetFloat(n)
+	default:
+		return fmt.Errorf("不支持的配置类型 %s", f.value.Type())
+	}
+	return nil
+}
+
+// format 返回配置项的字符串形式，与 set 接受的格式一致
+func (f field) format() string {
+	if m, ok := f.value.Addr().Interface().(encoding.TextMarshaler); ok {
+		text, _ := m.MarshalText()
+		return string(text)
+	}
+
+	switch v := f.value.Interface().(type) {
+	case time.Duration:
+		if unit, ok := durationUnits[f.unit]; ok && v%unit == 0 {
+			return strconv.FormatInt(int64(v/unit), 10)
+		}
+		return v.String()
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// splitList 解析逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
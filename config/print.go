@@ -0,0 +1,117 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 输出格式
+const (
+	PrintFormatYAML = "yaml"
+	PrintFormatEnv  = "env"
+)
+
+// redacted 敏感配置项输出时的替代值
+const redacted = "******"
+
+// Print 输出生效的配置，敏感配置项（密码、密钥等）不输出原值
+// yaml 格式可直接作为配置文件使用，env 格式可作为 .env 文件使用
+func (c *Config) Print(w io.Writer, format string) error {
+	switch format {
+	case PrintFormatYAML:
+		return c.printYAML(w)
+	case PrintFormatEnv:
+		return c.printEnv(w)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s，可选值为 yaml, env", format)
+	}
+}
+
+// display 返回配置项用于输出的值
+func (f field) display() string {
+	value := f.format()
+	if f.secret && value != "" {
+		return redacted
+	}
+	return value
+}
+
+func (c *Config) printEnv(w io.Writer) error {
+	for _, f := range fields(c) {
+		if f.env == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", f.env, f.display()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printYAML 按结构体声明顺序输出，保持与配置文件示例一致的顺序
+func (c *Config) printYAML(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := map[string]*yaml.Node{"": root}
+
+	for _, f := range fields(c) {
+		parent := root
+		keys := strings.Split(f.path, ".")
+		for i := range keys[:len(keys)-1] {
+			prefix := strings.Join(keys[:i+1], ".")
+			section, ok := sections[prefix]
+			if !ok {
+				section = &yaml.Node{Kind: yaml.MappingNode}
+				parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: keys[i]}, section)
+				sections[prefix] = section
+			}
+			parent = section
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: keys[len(keys)-1]}
+		value := f.node()
+		if f.unit != "" {
+			value.LineComment = "单位 " + f.unit
+		}
+		parent.Content = append(parent.Content, key, value)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// node 返回配置项对应的 YAML 节点，列表输出为序列
+func (f field) node() *yaml.Node {
+	if items, ok := f.value.Interface().([]string); ok && !f.secret {
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, item := range items {
+			seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+		}
+		return seq
+	}
+
+	// 日志级别等文本类型和不足一个单位的时长（如 1m30s）按字符串输出
+	value, tag := f.display(), "!!str"
+	if _, ok := f.value.Interface().(encoding.TextMarshaler); !ok && !f.secret {
+		switch f.value.Kind() {
+		case reflect.Bool:
+			tag = "!!bool"
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+				tag = "!!int"
+			}
+		case reflect.Float64:
+			tag = "!!float"
+		}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
@@ -3,54 +3,148 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"gaokao-data-analysis/utils"
 	"log/slog"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 )
 
-// initClickHouse 初始化 ClickHouse 数据库连接
-func initClickHouse() (*sql.DB, error) {
-	option := loadClickHouseConfig()
-	conn := clickhouse.OpenDB(option)
+// ClickHouseConfig ClickHouse 连接与查询设置
+type ClickHouseConfig struct {
+	Host     string `yaml:"host" env:"CLICKHOUSE_HOST"`
+	Port     int    `yaml:"port" env:"CLICKHOUSE_PORT"`
+	Database string `yaml:"database" env:"CLICKHOUSE_DATABASE"`
+	User     string `yaml:"user" env:"CLICKHOUSE_USER"`
+	Password string `yaml:"password" env:"CLICKHOUSE_PASSWORD" secret:"true"`
 
-	// 测试数据库连接
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// 随每次查询下发的设置，为 0 时使用服务端默认值
+	MaxExecutionTime time.Duration `yaml:"max_execution_time" env:"CLICKHOUSE_MAX_EXECUTION_TIME" unit:"s"`
+	MaxThreads       int           `yaml:"max_threads" env:"CLICKHOUSE_MAX_THREADS"`
+	MaxMemoryUsage   int64         `yaml:"max_memory_usage" env:"CLICKHOUSE_MAX_MEMORY_USAGE"` // 字节
+
+	// Pool 连接池配置
+	Pool PoolConfig `yaml:"pool" envPrefix:"CLICKHOUSE_"`
+}
+
+// DefaultClickHouseConfig 返回 ClickHouse 的默认配置
+func DefaultClickHouseConfig() ClickHouseConfig {
+	return ClickHouseConfig{
+		Host:             "localhost",
+		Port:             9000,
+		Database:         "default",
+		User:             "default",
+		MaxExecutionTime: time.Minute,
+		Pool: PoolConfig{
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: time.Hour,
+			ConnMaxIdleTime: 10 * time.Minute,
+		},
+	}
+}
+
+// Validate 校验 ClickHouse 配置
+func (c *ClickHouseConfig) Validate() error {
+	var errs []error
+	if c.Host == "" {
+		errs = append(errs, errors.New("CLICKHOUSE_HOST is required"))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("CLICKHOUSE_PORT is invalid: %d", c.Port))
+	}
+	if c.MaxExecutionTime < 0 || c.MaxThreads < 0 || c.MaxMemoryUsage < 0 {
+		errs = append(errs, errors.New("CLICKHOUSE_MAX_EXECUTION_TIME, CLICKHOUSE_MAX_THREADS and CLICKHOUSE_MAX_MEMORY_USAGE must not be negative"))
+	}
+	if err := c.Pool.Validate("CLICKHOUSE_"); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// maxClickHouseBackoff 后台重连 ClickHouse 的最长等待时间
+const maxClickHouseBackoff = time.Minute
+
+// openClickHouse 创建 ClickHouse 连接池，不建立连接，首次查询或 ping 时才连接
+func openClickHouse(cfg *ClickHouseConfig) *sql.DB {
+	// clickhouse.OpenDB 不接受 Options 中的连接池参数，需通过 sql.DB 设置
+	conn := clickhouse.OpenDB(cfg.options())
+	cfg.Pool.Apply(conn)
+	return conn
+}
+
+// pingClickHouse 检查 ClickHouse 连通性
+func pingClickHouse(ctx context.Context, conn *sql.DB, cfg *ClickHouseConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	if err := conn.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("连接 ClickHouse 失败: %w", err)
+		return fmt.Errorf("连接 ClickHouse 失败 (%s:%d/%s): %w", cfg.Host, cfg.Port, cfg.Database, err)
 	}
 
 	slog.Info("ClickHouse连接成功",
-		"host", option.Addr[0],
-		"database", option.Auth.Database,
+		"host", fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		"database", cfg.Database,
+		"settings", cfg.settings(),
 	)
+	return nil
+}
 
-	return conn, nil
+// reconnectClickHouse 在后台按指数退避重试连接 ClickHouse，连接成功或 ctx 取消后返回
+// 连接成功前健康检查报告 degraded，志愿推荐接口返回录取数据不可用
+func reconnectClickHouse(ctx context.Context, conn *sql.DB, cfg *ClickHouseConfig, backoff time.Duration) {
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if err := pingClickHouse(ctx, conn, cfg); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("ClickHouse连接失败，稍后重试", "attempt", attempt, "backoff", backoff.String(), "error", err.Error())
+			backoff = min(backoff*2, maxClickHouseBackoff)
+			continue
+		}
+		clickHouseReady.Store(true)
+		return
+	}
 }
 
-// loadClickHouseConfig 加载 ClickHouse 数据库配置
-func loadClickHouseConfig() *clickhouse.Options {
+// options 返回 ClickHouse 驱动使用的连接选项
+func (c *ClickHouseConfig) options() *clickhouse.Options {
 	return &clickhouse.Options{
-		Addr: []string{
-			fmt.Sprintf("%s:%d",
-				utils.GetEnv("CLICKHOUSE_HOST", "localhost"),
-				utils.GetIntEnv("CLICKHOUSE_PORT", 9000)),
-		},
+		Addr: []string{fmt.Sprintf("%s:%d", c.Host, c.Port)},
 		Auth: clickhouse.Auth{
-			Database: utils.GetEnv("CLICKHOUSE_DATABASE", "default"),
-			Username: utils.GetEnv("CLICKHOUSE_USER", "default"),
-			Password: utils.GetEnv("CLICKHOUSE_PASSWORD", ""),
-		},
-		Settings: clickhouse.Settings{
-			"max_execution_time": 60,
+			Database: c.Database,
+			Username: c.User,
+			Password: c.Password,
 		},
+		Settings:    c.settings(),
 		DialTimeout: 5 * time.Second,
 		Compression: &clickhouse.Compression{
 			Method: clickhouse.CompressionLZ4,
 		},
 	}
 }
+
+// settings 返回随每次查询下发的 ClickHouse 设置，值为 0 时使用服务端默认值
+func (c *ClickHouseConfig) settings() clickhouse.Settings {
+	settings := clickhouse.Settings{}
+	if c.MaxExecutionTime > 0 {
+		settings["max_execution_time"] = int(c.MaxExecutionTime.Seconds())
+	}
+	if c.MaxThreads > 0 {
+		settings["max_threads"] = c.MaxThreads
+	}
+	if c.MaxMemoryUsage > 0 {
+		settings["max_memory_usage"] = c.MaxMemoryUsage
+	}
+	return settings
+}
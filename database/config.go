@@ -0,0 +1,211 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config 关系型数据库与录取数据后端配置
+type Config struct {
+	Type     string `yaml:"type" env:"DB_TYPE"` // mysql、postgres 或 sqlite
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" env:"DB_PORT"` // 为 0 时按数据库类型取默认端口
+	User     string `yaml:"user" env:"DB_USER"` // 为空时按数据库类型取默认用户
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Database string `yaml:"database" env:"DB_DATABASE"`
+	Charset  string `yaml:"charset" env:"DB_CHARSET"`    // 仅 MySQL
+	SSLMode  string `yaml:"ssl_mode" env:"DB_SSL"`       // 仅 PostgreSQL
+	TimeZone string `yaml:"time_zone" env:"DB_TIMEZONE"` // 仅 PostgreSQL
+	Path     string `yaml:"path" env:"DB_PATH"`          // 仅 SQLite
+
+	// 启动时连接关系型数据库失败的重试次数与初始退避时间，每次重试退避时间翻倍
+	// ClickHouse 启动时只尝试一次，失败后在后台按同样的初始退避时间持续重连
+	ConnectRetries int           `yaml:"connect_retries" env:"DB_CONNECT_RETRIES"`
+	RetryBackoff   time.Duration `yaml:"retry_backoff" env:"DB_RETRY_BACKOFF_MS" unit:"ms"`
+
+	// Pool 连接池配置
+	Pool PoolConfig `yaml:"pool" envPrefix:"DB_"`
+
+	// AdmissionBackend 录取数据存储后端：clickhouse 或 sql
+	AdmissionBackend string `yaml:"admission_backend" env:"ADMISSION_BACKEND"`
+
+	// MigrateOnStart 启动时是否执行未执行的迁移，为 false 时只提示
+	MigrateOnStart bool `yaml:"migrate_on_start" env:"MIGRATE_ON_START"`
+}
+
+// PoolConfig 连接池配置，零值表示使用 database/sql 默认值
+// 环境变量名带所属连接的前缀，如 DB_MAX_OPEN_CONNS、CLICKHOUSE_MAX_OPEN_CONNS
+type PoolConfig struct {
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"CONN_MAX_LIFETIME_SECONDS" unit:"s"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"CONN_MAX_IDLE_SECONDS" unit:"s"`
+}
+
+// DefaultConfig 返回关系型数据库的默认配置
+func DefaultConfig() Config {
+	return Config{
+		Type:             "mysql",
+		Host:             "localhost",
+		Database:         "gaokao",
+		Charset:          "utf8mb4",
+		SSLMode:          "disable",
+		TimeZone:         "Asia/Shanghai",
+		Path:             "./database/gaokao.db",
+		ConnectRetries:   5,
+		RetryBackoff:     time.Second,
+		AdmissionBackend: AdmissionBackendClickHouse,
+		MigrateOnStart:   true,
+		Pool: PoolConfig{
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: time.Hour,
+			ConnMaxIdleTime: 10 * time.Minute,
+		},
+	}
+}
+
+// Normalize 统一数据库类型的写法，并按类型补全默认端口和用户
+func (c *Config) Normalize() {
+	c.Type = strings.ToLower(c.Type)
+	c.AdmissionBackend = strings.ToLower(c.AdmissionBackend)
+
+	switch c.Type {
+	case "postgres", "postgresql":
+		c.Type = "postgres"
+		if c.Port == 0 {
+			c.Port = 5432
+		}
+		if c.User == "" {
+			c.User = "postgres"
+		}
+	case "sqlite", "sqlite3":
+		c.Type = "sqlite"
+	case "mysql":
+		if c.Port == 0 {
+			c.Port = 3306
+		}
+		if c.User == "" {
+			c.User = "root"
+		}
+	}
+}
+
+// Validate 校验连接池配置
+func (p PoolConfig) Validate(prefix string) error {
+	var errs []error
+	if p.MaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("%sMAX_OPEN_CONNS must not be negative: %d", prefix, p.MaxOpenConns))
+	}
+	if p.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("%sMAX_IDLE_CONNS must not be negative: %d", prefix, p.MaxIdleConns))
+	}
+	if p.MaxOpenConns > 0 && p.MaxIdleConns > p.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("%sMAX_IDLE_CONNS (%d) must not exceed %sMAX_OPEN_CONNS (%d)",
+			prefix, p.MaxIdleConns, prefix, p.MaxOpenConns))
+	}
+	if p.ConnMaxLifetime < 0 || p.ConnMaxIdleTime < 0 {
+		errs = append(errs, fmt.Errorf("%sCONN_MAX_LIFETIME_SECONDS and %sCONN_MAX_IDLE_SECONDS must not be negative", prefix, prefix))
+	}
+	return errors.Join(errs...)
+}
+
+// Apply 将连接池配置应用到连接
+func (p PoolConfig) Apply(sqlDB *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// Validate 校验配置是否完整，返回所有问题
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Type {
+	case "mysql", "postgres":
+		if c.Host == "" {
+			errs = append(errs, errors.New("DB_HOST is required"))
+		}
+		if c.Port <= 0 || c.Port > 65535 {
+			errs = append(errs, fmt.Errorf("DB_PORT is invalid: %d", c.Port))
+		}
+		if c.User == "" {
+			errs = append(errs, errors.New("DB_USER is required"))
+		}
+		if c.Database == "" {
+			errs = append(errs, errors.New("DB_DATABASE is required"))
+		}
+	case "sqlite":
+		if c.Path == "" {
+			errs = append(errs, errors.New("DB_PATH is required"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported database type: %s", c.Type))
+	}
+
+	if c.ConnectRetries < 0 {
+		errs = append(errs, fmt.Errorf("DB_CONNECT_RETRIES must not be negative: %d", c.ConnectRetries))
+	}
+	if c.RetryBackoff < 0 {
+		errs = append(errs, fmt.Errorf("DB_RETRY_BACKOFF_MS must not be negative: %d", c.RetryBackoff.Milliseconds()))
+	}
+
+	if err := c.Pool.Validate("DB_"); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch c.AdmissionBackend {
+	case AdmissionBackendClickHouse, AdmissionBackendSQL:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported admission backend: %s", c.AdmissionBackend))
+	}
+
+	return errors.Join(errs...)
+}
+
+// DriverName 返回用于日志和错误信息的驱动名称
+func (c *Config) DriverName() string {
+	switch c.Type {
+	case "postgres":
+		return "PostgreSQL"
+	case "sqlite":
+		return "SQLite"
+	default:
+		return "MySQL"
+	}
+}
+
+// Address 返回不含密码的连接地址，用于日志和错误信息
+func (c *Config) Address() string {
+	if c.Type == "sqlite" {
+		return c.Path
+	}
+	return fmt.Sprintf("%s:%d/%s", c.Host, c.Port, c.Database)
+}
+
+// DSN 返回驱动使用的连接字符串
+func (c *Config) DSN() string {
+	switch c.Type {
+	case "postgres":
+		// PostgreSQL DSN format: host=localhost user=postgres password=... dbname=gaokao port=5432
+		return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
+			c.Host, c.User, c.Password, c.Database, c.Port, c.SSLMode, c.TimeZone)
+	case "sqlite":
+		return c.Path
+	default:
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=true&loc=Local",
+			c.User, c.Password, c.Host, c.Port, c.Database, c.Charset)
+	}
+}
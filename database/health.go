@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Backend health status
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDown     = "down"
+	HealthStatusDisabled = "disabled"
+)
+
+// PoolStats 连接池统计
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
+
+// BackendHealth 单个存储后端的健康状态
+type BackendHealth struct {
+	Status    string     `json:"status"`
+	LatencyMs float64    `json:"latency_ms"`
+	Error     string     `json:"error,omitempty"`
+	Pool      *PoolStats `json:"pool,omitempty"`
+}
+
+// CheckDatabaseHealth pings the relational database and reports pool stats
+func CheckDatabaseHealth(ctx context.Context) BackendHealth {
+	health := BackendHealth{Status: HealthStatusDown}
+	if db == nil {
+		health.Error = "database is not initialized"
+		return health
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	return pingBackend(ctx, sqlDB, health)
+}
+
+// CheckClickHouseHealth pings ClickHouse and reports pool stats.
+// ClickHouse is disabled when admission data is stored in the relational database.
+func CheckClickHouseHealth(ctx context.Context) BackendHealth {
+	health := BackendHealth{Status: HealthStatusDown}
+	if admissionBackend != AdmissionBackendClickHouse {
+		health.Status = HealthStatusDisabled
+		return health
+	}
+	if clickHouse == nil {
+		health.Error = "ClickHouse is not connected"
+		return health
+	}
+	health = pingBackend(ctx, clickHouse, health)
+	if health.Status == HealthStatusUp {
+		clickHouseReady.Store(true)
+	}
+	return health
+}
+
+// pingBackend 测量 ping 延迟并收集连接池统计
+func pingBackend(ctx context.Context, conn *sql.DB, health BackendHealth) BackendHealth {
+	start := time.Now()
+	err := conn.PingContext(ctx)
+	health.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+
+	if err != nil {
+		health.Error = err.Error()
+	} else {
+		health.Status = HealthStatusUp
+	}
+
+	stats := conn.Stats()
+	health.Pool = &PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+	return health
+}
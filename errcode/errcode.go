@@ -0,0 +1,186 @@
+package errcode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"gaokao-data-analysis/i18n"
+)
+
+// Code 稳定的机器可读错误码，客户端应根据错误码而不是 msg 判断错误类型
+type Code string
+
+// 通用错误
+const (
+	InvalidRequest Code = "INVALID_REQUEST"
+	NotImplemented Code = "NOT_IMPLEMENTED"
+	RateLimited    Code = "RATE_LIMITED"
+	Timeout        Code = "TIMEOUT"
+	Internal       Code = "INTERNAL_ERROR"
+	Unavailable    Code = "SERVICE_UNAVAILABLE"
+)
+
+// 鉴权与账号
+const (
+	Unauthenticated    Code = "UNAUTHENTICATED"
+	InvalidToken       Code = "INVALID_TOKEN"
+	InvalidCredentials Code = "INVALID_CREDENTIALS"
+	AccountNotFound    Code = "ACCOUNT_NOT_FOUND"
+	AccountExists      Code = "ACCOUNT_EXISTS"
+	Forbidden          Code = "FORBIDDEN"
+	UserNotFound       Code = "USER_NOT_FOUND"
+)
+
+// 档案
+const (
+	ProfileNotFound    Code = "PROFILE_NOT_FOUND"
+	ProfileForbidden   Code = "PROFILE_FORBIDDEN"
+	InvalidProfile     Code = "INVALID_PROFILE"
+	InvalidMember      Code = "INVALID_MEMBER"
+	InvalidImportFile  Code = "INVALID_IMPORT_FILE"
+	ImportFileTooLarge Code = "IMPORT_FILE_TOO_LARGE"
+	UnsupportedFormat  Code = "UNSUPPORTED_FORMAT"
+)
+
+// 志愿推荐与一分一段
+const (
+	InvalidSubjects      Code = "INVALID_SUBJECTS"
+	InvalidStrategy      Code = "INVALID_STRATEGY"
+	ScoreRankNotFound    Code = "SCORE_RANK_NOT_FOUND"
+	AdmissionUnavailable Code = "ADMISSION_DATA_UNAVAILABLE"
+)
+
+// definition 错误码对应的 HTTP 状态码和默认消息，消息为中文原文，译文登记在 i18n 包
+type definition struct {
+	status  int
+	message string
+}
+
+// catalog 错误码目录，新增错误码需在此登记
+var catalog = map[Code]definition{
+	InvalidRequest: {http.StatusBadRequest, "请求参数错误"},
+	NotImplemented: {http.StatusNotImplemented, "接口尚未实现"},
+	RateLimited:    {http.StatusTooManyRequests, "请求过于频繁，请稍后再试"},
+	Timeout:        {http.StatusGatewayTimeout, "查询超时，请稍后重试"},
+	Internal:       {http.StatusInternalServerError, "服务器内部错误"},
+	Unavailable:    {http.StatusServiceUnavailable, "服务暂不可用，请稍后重试"},
+
+	Unauthenticated:    {http.StatusUnauthorized, "请先登录"},
+	InvalidToken:       {http.StatusUnauthorized, "登录已失效，请重新登录"},
+	InvalidCredentials: {http.StatusUnauthorized, "账号或密码错误"},
+	AccountNotFound:    {http.StatusUnauthorized, "账号不存在"},
+	AccountExists:      {http.StatusConflict, "账号已存在"},
+	Forbidden:          {http.StatusForbidden, "没有权限执行该操作"},
+	UserNotFound:       {http.StatusNotFound, "用户不存在"},
+
+	ProfileNotFound:    {http.StatusNotFound, "档案不存在"},
+	ProfileForbidden:   {http.StatusForbidden, "没有权限访问该档案"},
+	InvalidProfile:     {http.StatusBadRequest, "档案信息有误"},
+	InvalidMember:      {http.StatusBadRequest, "成员信息有误"},
+	InvalidImportFile:  {http.StatusBadRequest, "导入文件有误"},
+	ImportFileTooLarge: {http.StatusRequestEntityTooLarge, "导入文件过大"},
+	UnsupportedFormat:  {http.StatusBadRequest, "不支持的文件格式"},
+
+	InvalidSubjects:      {http.StatusBadRequest, "科目组合有误"},
+	InvalidStrategy:      {http.StatusBadRequest, "策略参数有误，可选值为 0冲、1稳、2保"},
+	ScoreRankNotFound:    {http.StatusNotFound, "未找到对应的一分一段数据"},
+	AdmissionUnavailable: {http.StatusServiceUnavailable, "录取数据暂不可用，请稍后重试"},
+}
+
+// Status 返回错误码对应的 HTTP 状态码，未登记的错误码视为服务器内部错误
+func (c Code) Status() int {
+	if def, ok := catalog[c]; ok {
+		return def.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Message 返回错误码在指定语言下的默认消息
+func (c Code) Message(lang i18n.Lang) string {
+	def, ok := catalog[c]
+	if !ok {
+		def = catalog[Internal]
+	}
+	return i18n.T(lang, def.message)
+}
+
+// Error 带错误码的错误
+type Error struct {
+	Code Code
+	// Detail 返回给客户端的补充说明，如校验失败的字段；为中文原文或格式化模板，按响应语言翻译
+	Detail string
+	// Args Detail 的格式化参数，其中的 i18n.Localizable 同样按响应语言翻译
+	Args []interface{}
+	// Err 原始错误，只用于日志，不返回给客户端
+	Err error
+}
+
+// New 创建带补充说明的错误
+func New(code Code, detail string) *Error {
+	return &Error{Code: code, Detail: detail}
+}
+
+// Newf 使用格式化字符串创建带补充说明的错误，格式化在确定响应语言后进行
+func Newf(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Detail: format, Args: args}
+}
+
+// Wrap 使用错误码包装原始错误，原始错误不会返回给客户端
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Err: err}
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Detail != "" {
+		msg += ": " + i18n.T(i18n.Default, e.Detail, e.Args...)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Message 返回给客户端的消息：指定语言下的默认消息加补充说明
+func (e *Error) Message(lang i18n.Lang) string {
+	if e.Detail == "" {
+		return e.Code.Message(lang)
+	}
+	return e.Code.Message(lang) + ": " + i18n.T(lang, e.Detail, e.Args...)
+}
+
+// Coder 由可以映射为错误码的错误类型实现，错误内容作为补充说明返回给客户端
+// 同时实现 i18n.Localizable 时补充说明按响应语言输出，否则使用 Error()
+type Coder interface {
+	error
+	ErrorCode() Code
+}
+
+// From 将任意错误转换为 *Error
+// 已带错误码的直接返回，实现 Coder 的使用其错误码，超时映射为 Timeout，其余视为服务器内部错误
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var codeErr *Error
+	if errors.As(err, &codeErr) {
+		return codeErr
+	}
+	var coder Coder
+	if errors.As(err, &coder) {
+		if localizable, ok := coder.(i18n.Localizable); ok {
+			return &Error{Code: coder.ErrorCode(), Detail: "%s", Args: []interface{}{localizable}, Err: err}
+		}
+		return &Error{Code: coder.ErrorCode(), Detail: coder.Error(), Err: err}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Wrap(Timeout, err)
+	}
+	return Wrap(Internal, err)
+}
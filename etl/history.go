@@ -0,0 +1,149 @@
+package etl
+
+import "gaokao-data-analysis/models"
+
+// groupKey 专业组唯一标识
+type groupKey struct {
+	sourceProvince int8
+	schoolCode     string
+	groupCode      string
+}
+
+// BuildHistory 从招生计划记录中提取历年录取数据，写入历年录取数据表
+// 招生计划表中的 24 专业组数据、24 年专业录取数据对应招生计划年份的前一年；
+// 计划人数写入招生计划年份，下一年导入后即可计算计划人数的变化。专业组的计划人数为组内专业之和
+func BuildHistory(records []models.AdmissionRecord) []models.AdmissionHistory {
+	var history []models.AdmissionHistory
+	// 专业组在招生计划年份的数据行下标
+	groupPlans := make(map[groupKey]int)
+
+	for _, r := range records {
+		year := r.EnrollmentPlanYear - 1
+		key := models.AdmissionHistory{
+			SourceProvince:  r.SourceProvince,
+			SubjectCategory: r.SubjectCategory,
+			SchoolCode:      r.SchoolCode,
+			GroupCode:       r.MajorGroupCode,
+		}
+
+		// 同一专业组只写入一行上一年的录取数据和一行招生计划年份的数据
+		gk := groupKey{sourceProvince: r.SourceProvince, schoolCode: r.SchoolCode, groupCode: r.MajorGroupCode}
+		index, ok := groupPlans[gk]
+		if !ok {
+			group := key
+			group.AdmissionYearStat = models.AdmissionYearStat{
+				Year:         year,
+				MinScore:     nonZero(&r.MinScore2024),
+				MinRank:      nonZero(&r.MinRank2024),
+				AdmissionNum: nonZero(&r.AdmissionNum2024),
+			}
+			plan := key
+			plan.AdmissionYearStat = models.AdmissionYearStat{Year: r.EnrollmentPlanYear}
+			history = append(history, group, plan)
+			index = len(history) - 1
+			groupPlans[gk] = index
+		}
+		if r.EnrollmentPlan > 0 {
+			total := r.EnrollmentPlan
+			if history[index].PlanNum != nil {
+				total += *history[index].PlanNum
+			}
+			history[index].PlanNum = &total
+		}
+
+		if r.MajorCode == "" {
+			continue
+		}
+		major := key
+		major.MajorCode = r.MajorCode
+		major.AdmissionYearStat = models.AdmissionYearStat{
+			Year:         year,
+			MinScore:     nonZero(r.MajorMinScore2024),
+			MinRank:      nonZero(r.MajorMinRank2024),
+			AvgScore:     nonZero(r.MajorAvgScore2024),
+			AvgRank:      nonZero(r.MajorAvgRank2024),
+			MaxScore:     nonZero(r.MajorMaxScore2024),
+			MaxRank:      nonZero(r.MajorMaxRank2024),
+			AdmissionNum: nonZero(r.MajorAdmissionNum2024),
+		}
+		majorPlan := key
+		majorPlan.MajorCode = r.MajorCode
+		majorPlan.AdmissionYearStat = models.AdmissionYearStat{
+			Year:    r.EnrollmentPlanYear,
+			PlanNum: nonZero(&r.EnrollmentPlan),
+		}
+		history = append(history, major, majorPlan)
+	}
+	return history
+}
+
+// historyRowKey 历年录取数据表的唯一键
+type historyRowKey struct {
+	sourceProvince int8
+	schoolCode     string
+	groupCode      string
+	majorCode      string
+	year           int32
+}
+
+func historyKeyOf(h *models.AdmissionHistory) historyRowKey {
+	return historyRowKey{
+		sourceProvince: h.SourceProvince,
+		schoolCode:     h.SchoolCode,
+		groupCode:      h.GroupCode,
+		majorCode:      h.MajorCode,
+		year:           h.Year,
+	}
+}
+
+// mergeHistory 用表中已有的数据补全本次导入为空的字段
+// 同一年份的录取分数和计划人数来自相邻两年的招生计划表，写入时整行覆盖，需要先合并
+func mergeHistory(history, existing []models.AdmissionHistory) {
+	byKey := make(map[historyRowKey]*models.AdmissionYearStat, len(existing))
+	for i := range existing {
+		byKey[historyKeyOf(&existing[i])] = &existing[i].AdmissionYearStat
+	}
+
+	for i := range history {
+		old, ok := byKey[historyKeyOf(&history[i])]
+		if !ok {
+			continue
+		}
+		stat := &history[i].AdmissionYearStat
+		for _, field := range []struct{ dst, src **int32 }{
+			{&stat.MinScore, &old.MinScore},
+			{&stat.MinRank, &old.MinRank},
+			{&stat.AvgScore, &old.AvgScore},
+			{&stat.AvgRank, &old.AvgRank},
+			{&stat.MaxScore, &old.MaxScore},
+			{&stat.MaxRank, &old.MaxRank},
+			{&stat.AdmissionNum, &old.AdmissionNum},
+			{&stat.PlanNum, &old.PlanNum},
+		} {
+			if *field.dst == nil {
+				*field.dst = *field.src
+			}
+		}
+	}
+}
+
+// historyYears 返回数据涉及的年份
+func historyYears(history []models.AdmissionHistory) []int32 {
+	var years []int32
+	seen := make(map[int32]bool)
+	for _, h := range history {
+		if !seen[h.Year] {
+			seen[h.Year] = true
+			years = append(years, h.Year)
+		}
+	}
+	return years
+}
+
+func nonZero(value *int32) *int32 {
+	if value == nil || *value == 0 {
+		return nil
+	}
+	v := *value
+	return &v
+}
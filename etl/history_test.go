@@ -0,0 +1,158 @@
+package etl
+
+import (
+	"reflect"
+	"testing"
+
+	"gaokao-data-analysis/models"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+// historyRow 构造湖北物理类 10001 院校的历年录取数据行
+func historyRow(group, major string, stat models.AdmissionYearStat) models.AdmissionHistory {
+	return models.AdmissionHistory{
+		SourceProvince:    1,
+		SubjectCategory:   1,
+		SchoolCode:        "10001",
+		GroupCode:         group,
+		MajorCode:         major,
+		AdmissionYearStat: stat,
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	record := func(group, major string, plan int32) models.AdmissionRecord {
+		return models.AdmissionRecord{
+			SourceProvince:     1,
+			SubjectCategory:    1,
+			SchoolCode:         "10001",
+			MajorGroupCode:     group,
+			MajorCode:          major,
+			EnrollmentPlan:     plan,
+			EnrollmentPlanYear: 2025,
+		}
+	}
+
+	withScores := record("01", "080901", 10)
+	withScores.MinScore2024 = 600
+	withScores.MinRank2024 = 5000
+	withScores.AdmissionNum2024 = 20
+	withScores.MajorMinScore2024 = int32Ptr(605)
+	withScores.MajorMinRank2024 = int32Ptr(4500)
+	withScores.MajorAdmissionNum2024 = int32Ptr(0)
+
+	tests := []struct {
+		name    string
+		records []models.AdmissionRecord
+		want    []models.AdmissionHistory
+	}{
+		{
+			name: "没有数据",
+		},
+		{
+			name:    "专业组计划人数为组内专业之和",
+			records: []models.AdmissionRecord{withScores, record("01", "080902", 5)},
+			want: []models.AdmissionHistory{
+				historyRow("01", "", models.AdmissionYearStat{Year: 2024, MinScore: int32Ptr(600), MinRank: int32Ptr(5000), AdmissionNum: int32Ptr(20)}),
+				historyRow("01", "", models.AdmissionYearStat{Year: 2025, PlanNum: int32Ptr(15)}),
+				historyRow("01", "080901", models.AdmissionYearStat{Year: 2024, MinScore: int32Ptr(605), MinRank: int32Ptr(4500)}),
+				historyRow("01", "080901", models.AdmissionYearStat{Year: 2025, PlanNum: int32Ptr(10)}),
+				historyRow("01", "080902", models.AdmissionYearStat{Year: 2024}),
+				historyRow("01", "080902", models.AdmissionYearStat{Year: 2025, PlanNum: int32Ptr(5)}),
+			},
+		},
+		{
+			name:    "为0的分数和计划人数写入空值",
+			records: []models.AdmissionRecord{record("02", "080901", 0)},
+			want: []models.AdmissionHistory{
+				historyRow("02", "", models.AdmissionYearStat{Year: 2024}),
+				historyRow("02", "", models.AdmissionYearStat{Year: 2025}),
+				historyRow("02", "080901", models.AdmissionYearStat{Year: 2024}),
+				historyRow("02", "080901", models.AdmissionYearStat{Year: 2025}),
+			},
+		},
+		{
+			name:    "没有专业代码时只写入专业组",
+			records: []models.AdmissionRecord{record("03", "", 8)},
+			want: []models.AdmissionHistory{
+				historyRow("03", "", models.AdmissionYearStat{Year: 2024}),
+				historyRow("03", "", models.AdmissionYearStat{Year: 2025, PlanNum: int32Ptr(8)}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildHistory(tt.records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildHistory() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeHistory(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  []models.AdmissionHistory
+		existing []models.AdmissionHistory
+		want     []models.AdmissionHistory
+	}{
+		{
+			name: "表中没有数据",
+			history: []models.AdmissionHistory{
+				historyRow("01", "", models.AdmissionYearStat{Year: 2025, PlanNum: int32Ptr(15)}),
+			},
+			want: []models.AdmissionHistory{
+				historyRow("01", "", models.AdmissionYearStat{Year: 2025, PlanNum: int32Ptr(15)}),
+			},
+		},
+		{
+			name: "计划年份的计划人数保留上一年导入的录取分数",
+			history: []models.AdmissionHistory{
+				historyRow("01", "", models.AdmissionYearStat{Year: 2025, PlanNum: int32Ptr(15)}),
+			},
+			existing: []models.AdmissionHistory{
+				historyRow("01", "", models.AdmissionYearStat{Year: 2025, MinScore: int32Ptr(600), MinRank: int32Ptr(5000)}),
+			},
+			want: []models.AdmissionHistory{
+				historyRow("01", "", models.AdmissionYearStat{Year: 2025, MinScore: int32Ptr(600), MinRank: int32Ptr(5000), PlanNum: int32Ptr(15)}),
+			},
+		},
+		{
+			name: "本次导入的数据优先",
+			history: []models.AdmissionHistory{
+				historyRow("01", "080901", models.AdmissionYearStat{Year: 2024, MinScore: int32Ptr(605)}),
+			},
+			existing: []models.AdmissionHistory{
+				historyRow("01", "080901", models.AdmissionYearStat{Year: 2024, MinScore: int32Ptr(590), MaxScore: int32Ptr(630), PlanNum: int32Ptr(10)}),
+			},
+			want: []models.AdmissionHistory{
+				historyRow("01", "080901", models.AdmissionYearStat{Year: 2024, MinScore: int32Ptr(605), MaxScore: int32Ptr(630), PlanNum: int32Ptr(10)}),
+			},
+		},
+		{
+			name: "年份或专业不同的数据不合并",
+			history: []models.AdmissionHistory{
+				historyRow("01", "080901", models.AdmissionYearStat{Year: 2025}),
+			},
+			existing: []models.AdmissionHistory{
+				historyRow("01", "080901", models.AdmissionYearStat{Year: 2024, PlanNum: int32Ptr(10)}),
+				historyRow("01", "", models.AdmissionYearStat{Year: 2025, PlanNum: int32Ptr(15)}),
+				historyRow("02", "080901", models.AdmissionYearStat{Year: 2025, PlanNum: int32Ptr(8)}),
+			},
+			want: []models.AdmissionHistory{
+				historyRow("01", "080901", models.AdmissionYearStat{Year: 2025}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mergeHistory(tt.history, tt.existing)
+			if !reflect.DeepEqual(tt.history, tt.want) {
+				t.Errorf("mergeHistory() = %+v, want %+v", tt.history, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package etl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"gaokao-data-analysis/database"
+	"gaokao-data-analysis/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sink 录取数据写入目标
+type Sink interface {
+	// NextID 返回下一条记录可用的 ID
+	NextID(ctx context.Context) (uint32, error)
+	// Open 开始一次导入，replace 为 true 时提交后以本次数据替换 gaokao2025 的全部数据
+	// 提交前写入的数据对查询不可见，导入失败时原有数据保持不变
+	Open(ctx context.Context, replace bool) (Writer, error)
+	// ListHistory 查询指定年份的历年录取数据
+	ListHistory(ctx context.Context, years []int32) ([]models.AdmissionHistory, error)
+	// InsertHistory 写入历年录取数据，相同 (省份, 院校, 专业组, 专业, 年份) 的数据会被覆盖
+	InsertHistory(ctx context.Context, history []models.AdmissionHistory) error
+}
+
+// Writer 一次导入的 gaokao2025 数据写入
+type Writer interface {
+	// Insert 批量写入记录
+	Insert(ctx context.Context, records []models.AdmissionRecord) error
+	// Commit 提交本次导入
+	Commit(ctx context.Context) error
+	// Abort 放弃本次导入，丢弃已写入的记录
+	Abort(ctx context.Context) error
+}
+
+// GetSink 根据 ADMISSION_BACKEND 配置返回写入目标
+func GetSink() (Sink, error) {
+	switch database.GetAdmissionBackend() {
+	case database.AdmissionBackendSQL:
+		db := database.GetDB()
+		if db == nil {
+			return nil, fmt.Errorf("数据库连接未初始化")
+		}
+		return &gormSink{db: db}, nil
+	default:
+		db := database.GetClickHouse()
+		if db == nil {
+			return nil, fmt.Errorf("ClickHouse连接未初始化")
+		}
+		return &clickHouseSink{db: db}, nil
+	}
+}
+
+// clickHouseSink 写入 ClickHouse，一个批次对应一次 INSERT
+type clickHouseSink struct {
+	db *sql.DB
+}
+
+func (s *clickHouseSink) NextID(ctx context.Context) (uint32, error) {
+	var maxID uint32
+	if err := s.db.QueryRowContext(ctx, "SELECT max(id) FROM "+models.TABLE).Scan(&maxID); err != nil {
+		return 0, err
+	}
+	return maxID + 1, nil
+}
+
+// stagingTable ClickHouse 导入使用的暂存表
+var stagingTable = models.TABLE + "_staging"
+
+// Open 创建与 gaokao2025 结构相同的暂存表，记录先写入暂存表，提交时再并入或替换原表
+func (s *clickHouseSink) Open(ctx context.Context, replace bool) (Writer, error) {
+	if _, err := s.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+stagingTable); err != nil {
+		return nil, err
+	}
+	if _, err := s.db.ExecContext(ctx, "CREATE TABLE "+stagingTable+" AS "+models.TABLE); err != nil {
+		return nil, fmt.Errorf("创建暂存表失败: %w", err)
+	}
+	return &clickHouseWriter{db: s.db, staging: stagingTable, replace: replace}, nil
+}
+
+// clickHouseWriter 写入暂存表，一个批次对应一次 INSERT
+type clickHouseWriter struct {
+	db      *sql.DB
+	staging string
+	replace bool
+}
+
+func (w *clickHouseWriter) Insert(ctx context.Context, records []models.AdmissionRecord) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO `+w.staging+` (
+	id, major_id, school_name, school_code, major_group_code, major_name, major_code, major_category,
+	major_description, source_province, subject_category, subject_requirement_raw, tuition_fee,
+	is_new_major, admission_batch, enrollment_plan, enrollment_type, enrollment_plan_year,
+	enrollment_plan_2024, study_duration, require_physics, require_chemistry, require_biology,
+	require_politics, require_history, require_geography, min_score_2024, min_rank_2024,
+	admission_num_2024, major_min_score_2024, major_min_rank_2024, major_avg_score_2024,
+	major_avg_rank_2024, major_max_score_2024, major_max_rank_2024, major_admission_num_2024,
+	school_province, school_city, school_type, school_ownership, school_authority, school_level,
+	school_tags, education_level
+)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	// ClickHouse 表的数值列不可为空，缺失值按 0 写入，与 notebook 的 fillna(0) 一致
+	for _, r := range records {
+		_, err := stmt.ExecContext(ctx,
+			r.ID, r.MajorID, r.SchoolName, r.SchoolCode, r.MajorGroupCode, r.MajorName, r.MajorCode, r.MajorCategory,
+			stringValue(r.MajorDescription), r.SourceProvince, r.SubjectCategory, r.SubjectRequirementRaw, stringValue(r.TuitionFee),
+			r.IsNewMajor, r.AdmissionBatch, uint16(r.EnrollmentPlan), r.EnrollmentType, uint16(r.EnrollmentPlanYear),
+			uint16(intValue(r.EnrollmentPlan2024)), uint8(intValue(r.StudyDuration)), r.RequirePhysics, r.RequireChemistry, r.RequireBiology,
+			r.RequirePolitics, r.RequireHistory, r.RequireGeography, uint16(r.MinScore2024), uint32(r.MinRank2024),
+			uint16(r.AdmissionNum2024), uint16(intValue(r.MajorMinScore2024)), uint32(intValue(r.MajorMinRank2024)), uint16(intValue(r.MajorAvgScore2024)),
+			uint32(intValue(r.MajorAvgRank2024)), uint16(intValue(r.MajorMaxScore2024)), uint32(intValue(r.MajorMaxRank2024)), uint16(intValue(r.MajorAdmissionNum2024)),
+			r.SchoolProvince, r.SchoolCity, r.SchoolType, r.SchoolOwnership, r.SchoolAuthority, r.SchoolLevel,
+			r.SchoolTags, r.EducationLevel,
+		)
+		if err != nil {
+			return fmt.Errorf("append row id=%d: %w", r.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Commit 替换时用 EXCHANGE TABLES 原子交换暂存表与原表（需要 Atomic 数据库引擎），追加时将暂存表整体写入原表
+func (w *clickHouseWriter) Commit(ctx context.Context) error {
+	if w.replace {
+		if _, err := w.db.ExecContext(ctx, "EXCHANGE TABLES "+w.staging+" AND "+models.TABLE); err != nil {
+			return fmt.Errorf("替换数据表失败: %w", err)
+		}
+	} else {
+		if _, err := w.db.ExecContext(ctx, "INSERT INTO "+models.TABLE+" SELECT * FROM "+w.staging); err != nil {
+			return fmt.Errorf("写入数据表失败: %w", err)
+		}
+	}
+
+	// 数据已生效，替换后暂存表中是原有数据，删除失败只影响磁盘占用，下次导入时会重新创建
+	if err := w.Abort(ctx); err != nil {
+		slog.Warn("删除暂存表失败", "table", w.staging, "error", err)
+	}
+	return nil
+}
+
+// Abort 删除暂存表，原表不受影响
+func (w *clickHouseWriter) Abort(ctx context.Context) error {
+	_, err := w.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+w.staging)
+	return err
+}
+
+func (s *clickHouseSink) ListHistory(ctx context.Context, years []int32) ([]models.AdmissionHistory, error) {
+	if len(years) == 0 {
+		return nil, nil
+	}
+	placeholders := make([]string, len(years))
+	args := make([]interface{}, len(years))
+	for i, year := range years {
+		placeholders[i] = "?"
+		args[i] = year
+	}
+
+	// 枚举列转换为整数，与写入时使用的枚举值一致
+	rows, err := s.db.QueryContext(ctx, `SELECT
+	CAST(source_province AS Int8), CAST(subject_category AS Int8), school_code, major_group_code, major_code, year,
+	min_score, min_rank, avg_score, avg_rank, max_score, max_rank, admission_num, plan_num
+FROM `+models.AdmissionHistoryTable+` FINAL
+WHERE year IN (`+strings.Join(placeholders, ", ")+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.AdmissionHistory
+	for rows.Next() {
+		var h models.AdmissionHistory
+		if err := rows.Scan(
+			&h.SourceProvince, &h.SubjectCategory, &h.SchoolCode, &h.GroupCode, &h.MajorCode, &h.Year,
+			&h.MinScore, &h.MinRank, &h.AvgScore, &h.AvgRank, &h.MaxScore, &h.MaxRank, &h.AdmissionNum, &h.PlanNum,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+func (s *clickHouseSink) InsertHistory(ctx context.Context, history []models.AdmissionHistory) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO `+models.AdmissionHistoryTable+` (
+	source_province, subject_category, school_code, major_group_code, major_code, year,
+	min_score, min_rank, avg_score, avg_rank, max_score, max_rank, admission_num, plan_num
+)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	// admission_history 为 ReplacingMergeTree，重复写入由后台合并及查询时的 FINAL 去重
+	for _, h := range history {
+		_, err := stmt.ExecContext(ctx,
+			h.SourceProvince, h.SubjectCategory, h.SchoolCode, h.GroupCode, h.MajorCode, uint16(h.Year),
+			uint16Ptr(h.MinScore), uint32Ptr(h.MinRank), uint16Ptr(h.AvgScore), uint32Ptr(h.AvgRank),
+			uint16Ptr(h.MaxScore), uint32Ptr(h.MaxRank), uint16Ptr(h.AdmissionNum), uint16Ptr(h.PlanNum),
+		)
+		if err != nil {
+			return fmt.Errorf("append history school=%s group=%s major=%s: %w", h.SchoolCode, h.GroupCode, h.MajorCode, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// gormSink 写入关系型数据库（ADMISSION_BACKEND=sql）
+type gormSink struct {
+	db *gorm.DB
+}
+
+func (s *gormSink) NextID(ctx context.Context) (uint32, error) {
+	var maxID sql.NullInt64
+	if err := s.db.WithContext(ctx).Model(&models.AdmissionRecord{}).Select("MAX(id)").Scan(&maxID).Error; err != nil {
+		return 0, err
+	}
+	return uint32(maxID.Int64) + 1, nil
+}
+
+// Open 开启事务，替换时在事务中清空原表，提交事务后其它连接才能看到变化
+func (s *gormSink) Open(ctx context.Context, replace bool) (Writer, error) {
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if replace {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.AdmissionRecord{}).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("清空数据表失败: %w", err)
+		}
+	}
+	return &gormWriter{tx: tx}, nil
+}
+
+// gormWriter 在一个事务中写入全部记录
+type gormWriter struct {
+	tx *gorm.DB
+}
+
+func (w *gormWriter) Insert(ctx context.Context, records []models.AdmissionRecord) error {
+	// 按 500 行分批，避免超出数据库单条语句的参数数量限制
+	return w.tx.WithContext(ctx).CreateInBatches(&records, 500).Error
+}
+
+func (w *gormWriter) Commit(ctx context.Context) error {
+	return w.tx.Commit().Error
+}
+
+func (w *gormWriter) Abort(ctx context.Context) error {
+	return w.tx.Rollback().Error
+}
+
+func (s *gormSink) ListHistory(ctx context.Context, years []int32) ([]models.AdmissionHistory, error) {
+	var history []models.AdmissionHistory
+	if err := s.db.WithContext(ctx).Where("year IN ?", years).Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (s *gormSink) InsertHistory(ctx context.Context, history []models.AdmissionHistory) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "source_province"}, {Name: "school_code"}, {Name: "major_group_code"}, {Name: "major_code"}, {Name: "year"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"subject_category", "min_score", "min_rank", "avg_score", "avg_rank",
+			"max_score", "max_rank", "admission_num", "plan_num",
+		}),
+	}).CreateInBatches(&history, 500).Error
+}
+
+// LoadOptions 导入参数
+type LoadOptions struct {
+	// Replace 以导入的数据替换表中全部数据，否则追加并从现有最大 ID 之后编号
+	Replace bool
+	// DryRun 只校验不写入
+	DryRun bool
+	// BatchSize 每批写入的行数
+	BatchSize int
+}
+
+// LoadResult 导入结果
+type LoadResult struct {
+	Total    int        `json:"total"`
+	Loaded   int        `json:"loaded"`
+	History  int        `json:"history"`
+	Rejected []RowError `json:"rejected"`
+}
+
+// Load 校验并导入数据表，被拒绝的行记录在结果中，不影响其它行导入
+func Load(ctx context.Context, table *Table, sink Sink, opts LoadOptions) (*LoadResult, error) {
+	transformer, err := NewTransformer(table.Header)
+	if err != nil {
+		return nil, err
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 5000
+	}
+
+	firstID := uint32(1)
+	if !opts.Replace && !opts.DryRun {
+		if firstID, err = sink.NextID(ctx); err != nil {
+			return nil, fmt.Errorf("查询现有最大ID失败: %w", err)
+		}
+	}
+
+	records, rejected := transformer.Transform(table, firstID)
+	result := &LoadResult{
+		Total:    len(records) + len(rejected),
+		Rejected: rejected,
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	writer, err := sink.Open(ctx, opts.Replace)
+	if err != nil {
+		return nil, fmt.Errorf("开始导入失败: %w", err)
+	}
+	if opts.Replace {
+		slog.Warn("导入完成后将替换录取数据表中的全部数据", "table", models.TABLE)
+	}
+
+	// 写入失败时放弃整个导入，表中数据保持导入前的状态
+	for start := 0; start < len(records); start += opts.BatchSize {
+		end := min(start+opts.BatchSize, len(records))
+		if err := writer.Insert(ctx, records[start:end]); err != nil {
+			if abortErr := writer.Abort(ctx); abortErr != nil {
+				slog.Error("放弃导入失败", "error", abortErr)
+			}
+			return result, fmt.Errorf("写入第 %d-%d 条记录失败，本次导入未生效: %w", start+1, end, err)
+		}
+		slog.Info("写入录取数据", "written", end, "total", len(records))
+	}
+	if err := writer.Commit(ctx); err != nil {
+		return result, fmt.Errorf("提交导入失败: %w", err)
+	}
+	result.Loaded = len(records)
+
+	// 同一年份的录取分数和计划人数分别来自相邻两年的招生计划表，写入前与表中已有数据合并
+	history := BuildHistory(records)
+	existing, err := sink.ListHistory(ctx, historyYears(history))
+	if err != nil {
+		return result, fmt.Errorf("查询已有历年录取数据失败: %w", err)
+	}
+	mergeHistory(history, existing)
+	for start := 0; start < len(history); start += opts.BatchSize {
+		end := min(start+opts.BatchSize, len(history))
+		if err := sink.InsertHistory(ctx, history[start:end]); err != nil {
+			return result, fmt.Errorf("写入第 %d-%d 条历年录取数据失败: %w", start+1, end, err)
+		}
+		result.History = end
+	}
+	slog.Info("写入历年录取数据", "count", result.History)
+	return result, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func intValue(value *int32) int32 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func uint16Ptr(value *int32) *uint16 {
+	if value == nil {
+		return nil
+	}
+	v := uint16(*value)
+	return &v
+}
+
+func uint32Ptr(value *int32) *uint32 {
+	if value == nil {
+		return nil
+	}
+	v := uint32(*value)
+	return &v
+}
@@ -0,0 +1,132 @@
+package etl
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Table 原始数据表，Header 为展开后的列名，Rows 不含表头
+type Table struct {
+	Header []string
+	Rows   [][]string
+	// HeaderRows 表头所占行数，用于计算数据行在文件中的行号
+	HeaderRows int
+}
+
+// Line 返回第 i 条数据在文件中的行号（从 1 开始）
+func (t *Table) Line(i int) int {
+	return t.HeaderRows + i + 1
+}
+
+// ReadTable 读取 CSV 或 XLSX（第一个工作表）文件
+// 招生计划表为多行表头，如 "2025招生计划 / 院校代码"，展开为 "2025招生计划_院校代码"；
+// 与 scripts/db_migrate.ipynb 一致，只使用最后两行表头，合并单元格向右填充
+func ReadTable(path string, headerRows int) (*Table, error) {
+	if headerRows < 1 {
+		return nil, fmt.Errorf("header rows must be at least 1: %d", headerRows)
+	}
+
+	var (
+		records [][]string
+		err     error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		records, err = readCSV(path)
+	case ".xlsx":
+		records, err = readXLSX(path)
+	default:
+		return nil, fmt.Errorf("unsupported file type: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < headerRows {
+		return nil, errors.New("文件没有表头")
+	}
+
+	return &Table{
+		Header:     flattenHeader(records[:headerRows]),
+		Rows:       records[headerRows:],
+		HeaderRows: headerRows,
+	}, nil
+}
+
+func readCSV(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析CSV失败: %w", err)
+	}
+	// 去除 Excel 导出 CSV 时带的 UTF-8 BOM
+	if len(records) > 0 && len(records[0]) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	}
+	return records, nil
+}
+
+func readXLSX(path string) ([][]string, error) {
+	file, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("解析XLSX失败: %w", err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("XLSX文件没有工作表")
+	}
+	return file.GetRows(sheets[0])
+}
+
+// flattenHeader 将多行表头展开为单行列名
+func flattenHeader(rows [][]string) []string {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	cell := func(row []string, i int) string {
+		if i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	header := make([]string, width)
+	last := rows[len(rows)-1]
+	if len(rows) == 1 {
+		for i := range header {
+			header[i] = cell(last, i)
+		}
+		return header
+	}
+
+	// 倒数第二行为分组名，合并单元格只有第一个单元格有值，向右填充
+	group := rows[len(rows)-2]
+	current := ""
+	for i := range header {
+		if value := cell(group, i); value != "" {
+			current = value
+		}
+		if current != "" {
+			header[i] = current + "_" + cell(last, i)
+		} else {
+			header[i] = cell(last, i)
+		}
+	}
+	return header
+}
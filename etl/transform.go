@@ -0,0 +1,353 @@
+package etl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gaokao-data-analysis/models"
+)
+
+// 招生计划表列名（多行表头展开后），与 scripts/db_migrate.ipynb 一致
+const (
+	colSchoolName         = "2025招生计划_院校"
+	colSchoolCode         = "2025招生计划_院校代码"
+	colGroupCode          = "2025招生计划_专业组代码"
+	colMajorName          = "2025招生计划_专业"
+	colMajorCode          = "2025招生计划_专业代码"
+	colMajorCategory      = "2025招生计划_专业类"
+	colMajorDescription   = "2025招生计划_专业备注"
+	colSourceProvince     = "2025招生计划_省份"
+	colSubjectCategory    = "2025招生计划_科目"
+	colSubjectRequirement = "2025招生计划_选科要求"
+	colTuitionFee         = "2025招生计划_学费"
+	colIsNewMajor         = "2025招生计划_是否新增"
+	colAdmissionBatch     = "2025招生计划_批次"
+	colEnrollmentPlan     = "2025招生计划_计划人数"
+	colEnrollmentType     = "2025招生计划_类型"
+	colEnrollmentYear     = "2025招生计划_年份"
+	colStudyDuration      = "2025招生计划_学制"
+
+	colGroupMinScore     = "24专业组数据_最低分"
+	colGroupMinRank      = "24专业组数据_最低分位次"
+	colGroupAdmissionNum = "24专业组数据_录取人数"
+	colMajorMinScore     = "24年专业录取数据_最低分1"
+	colMajorMinRank      = "24年专业录取数据_最低分位次1"
+	colMajorAvgScore     = "24年专业录取数据_平均分1"
+	colMajorAvgRank      = "24年专业录取数据_平均分位次1"
+	colMajorMaxScore     = "24年专业录取数据_最高分1"
+	colMajorMaxRank      = "24年专业录取数据_最高分位次1"
+	colMajorAdmissionNum = "24年专业录取数据_录取人数1"
+
+	colSchoolProvince  = "院校信息介绍_院校省份"
+	colSchoolCity      = "院校信息介绍_院校城市"
+	colSchoolType      = "院校信息介绍_院校类型"
+	colSchoolOwnership = "院校信息介绍_办学性质"
+	colSchoolAuthority = "院校信息介绍_隶属部门"
+	colSchoolTags      = "院校信息介绍_院校标签"
+	colEducationLevel  = "院校信息介绍_院校层级"
+)
+
+// requiredColumns 缺少时整个文件无法导入的列
+var requiredColumns = []string{
+	colSchoolName, colSchoolCode, colGroupCode, colMajorName, colMajorCode,
+	colSourceProvince, colSubjectCategory, colSubjectRequirement, colAdmissionBatch,
+	colEnrollmentPlan, colSchoolOwnership, colEducationLevel,
+}
+
+// schoolLevelTags 从院校标签中提取的院校层次
+var schoolLevelTags = []string{"211", "985", "双一流"}
+
+// 选科要求中各科目的简称，生物需先于物理识别，避免“生物”中的“物”被误判为物理
+var subjectAbbreviations = []struct {
+	abbr  string
+	apply func(r *models.AdmissionRecord)
+}{
+	{"生", func(r *models.AdmissionRecord) { r.RequireBiology = true }},
+	{"物", func(r *models.AdmissionRecord) { r.RequirePhysics = true }},
+	{"化", func(r *models.AdmissionRecord) { r.RequireChemistry = true }},
+	{"政", func(r *models.AdmissionRecord) { r.RequirePolitics = true }},
+	{"历", func(r *models.AdmissionRecord) { r.RequireHistory = true }},
+	{"地", func(r *models.AdmissionRecord) { r.RequireGeography = true }},
+}
+
+// RowError 被拒绝的数据行，Line 为文件中的行号
+type RowError struct {
+	Line  int    `json:"line"`
+	Field string `json:"field,omitempty"`
+	Msg   string `json:"msg"`
+}
+
+func (e *RowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Msg)
+}
+
+// Transformer 将招生计划表的数据行转换为 gaokao2025 记录
+type Transformer struct {
+	enumMapper *models.EnumMapper
+	columns    map[string]int
+}
+
+// NewTransformer 根据表头创建转换器，缺少必需列时返回错误
+func NewTransformer(header []string) (*Transformer, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, exists := columns[name]; !exists {
+			columns[name] = i
+		}
+	}
+
+	var missing []string
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("文件缺少必需列: %s", strings.Join(missing, ", "))
+	}
+
+	return &Transformer{enumMapper: models.NewEnumMapper(), columns: columns}, nil
+}
+
+// Transform 转换全部数据行，跳过空行，ID 由 firstID 开始按顺序分配给通过校验的行
+func (t *Transformer) Transform(table *Table, firstID uint32) ([]models.AdmissionRecord, []RowError) {
+	var records []models.AdmissionRecord
+	var rejected []RowError
+
+	nextID := firstID
+	for i, row := range table.Rows {
+		if isBlankRow(row) {
+			continue
+		}
+
+		record, err := t.transformRow(row)
+		if err != nil {
+			err.Line = table.Line(i)
+			rejected = append(rejected, *err)
+			continue
+		}
+		record.ID = nextID
+		nextID++
+		records = append(records, *record)
+	}
+	return records, rejected
+}
+
+// transformRow 转换并校验单行数据
+func (t *Transformer) transformRow(row []string) (*models.AdmissionRecord, *RowError) {
+	p := &rowParser{row: row, columns: t.columns}
+	record := &models.AdmissionRecord{
+		SchoolName:            p.required(colSchoolName),
+		SchoolCode:            p.required(colSchoolCode),
+		MajorGroupCode:        p.groupCode(colGroupCode),
+		MajorName:             p.required(colMajorName),
+		MajorCode:             p.required(colMajorCode),
+		MajorCategory:         p.text(colMajorCategory),
+		SubjectRequirementRaw: p.text(colSubjectRequirement),
+		TuitionFee:            p.optionalText(colTuitionFee),
+		EnrollmentPlan:        p.number(colEnrollmentPlan, math.MaxUint16),
+		EnrollmentPlanYear:    p.number(colEnrollmentYear, math.MaxUint16),
+		MinScore2024:          p.number(colGroupMinScore, math.MaxUint16),
+		MinRank2024:           p.number(colGroupMinRank, math.MaxInt32),
+		AdmissionNum2024:      p.number(colGroupAdmissionNum, math.MaxUint16),
+		MajorMinScore2024:     p.optionalNumber(colMajorMinScore, math.MaxUint16),
+		MajorMinRank2024:      p.optionalNumber(colMajorMinRank, math.MaxInt32),
+		MajorAvgScore2024:     p.optionalNumber(colMajorAvgScore, math.MaxUint16),
+		MajorAvgRank2024:      p.optionalNumber(colMajorAvgRank, math.MaxInt32),
+		MajorMaxScore2024:     p.optionalNumber(colMajorMaxScore, math.MaxUint16),
+		MajorMaxRank2024:      p.optionalNumber(colMajorMaxRank, math.MaxInt32),
+		MajorAdmissionNum2024: p.optionalNumber(colMajorAdmissionNum, math.MaxUint16),
+		StudyDuration:         p.optionalNumber(colStudyDuration, math.MaxUint8),
+		SchoolProvince:        p.text(colSchoolProvince),
+		SchoolCity:            p.text(colSchoolCity),
+		SchoolType:            unifyTags(p.text(colSchoolType)),
+		SchoolAuthority:       p.text(colSchoolAuthority),
+		SchoolTags:            unifyTags(p.text(colSchoolTags)),
+	}
+	if description := p.text(colMajorDescription); description != "" {
+		record.MajorDescription = &description
+	}
+	if record.EnrollmentPlanYear == 0 {
+		record.EnrollmentPlanYear = 2025
+	}
+
+	record.SourceProvince = p.enum(colSourceProvince, t.enumMapper.MapProvince)
+	record.SubjectCategory = p.enum(colSubjectCategory, t.enumMapper.MapSubjectCategory)
+	record.AdmissionBatch = p.enum(colAdmissionBatch, t.enumMapper.MapAdmissionBatch)
+	record.EnrollmentType = p.enum(colEnrollmentType, t.enumMapper.MapEnrollmentType)
+	record.SchoolOwnership = p.enum(colSchoolOwnership, t.enumMapper.MapOwnership)
+	record.EducationLevel = p.enum(colEducationLevel, t.enumMapper.MapEducationLevel)
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	record.MajorID = record.SchoolCode + record.MajorGroupCode + record.MajorCode
+	record.SchoolLevel = extractSchoolLevel(record.SchoolTags)
+	applySubjectRequirement(record, p.text(colSubjectCategory))
+	record.IsNewMajor = t.isNewMajor(p, record)
+
+	return record, nil
+}
+
+// isNewMajor 以“是否新增”列为准；文件没有该列时，专业没有上一年录取数据即视为新增
+func (t *Transformer) isNewMajor(p *rowParser, record *models.AdmissionRecord) bool {
+	if _, ok := t.columns[colIsNewMajor]; ok {
+		return p.text(colIsNewMajor) == "新增"
+	}
+	return record.MajorMinScore2024 == nil && record.MajorAdmissionNum2024 == nil
+}
+
+// applySubjectRequirement 将科类与选科要求拆分为 require_* 字段
+// 首选科目（物理/历史）由科类决定，再选科目从选科要求中识别，“不限”不设置任何要求
+func applySubjectRequirement(record *models.AdmissionRecord, subjectCategory string) {
+	switch subjectCategory {
+	case "物理":
+		record.RequirePhysics = true
+	case "历史":
+		record.RequireHistory = true
+	}
+
+	raw := strings.ReplaceAll(record.SubjectRequirementRaw, "生物", "生")
+	for _, subject := range subjectAbbreviations {
+		if strings.Contains(raw, subject.abbr) {
+			subject.apply(record)
+			raw = strings.ReplaceAll(raw, subject.abbr, "")
+		}
+	}
+}
+
+// unifyTags 将标签分隔符统一为英文逗号，去掉空标签
+func unifyTags(value string) string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '，' || r == '/' || r == ' '
+	})
+	return strings.Join(fields, ",")
+}
+
+// extractSchoolLevel 从院校标签中提取院校层次，保持 schoolLevelTags 的顺序
+func extractSchoolLevel(tags string) string {
+	tagSet := make(map[string]bool)
+	for _, tag := range strings.Split(tags, ",") {
+		tagSet[tag] = true
+	}
+
+	var levels []string
+	for _, level := range schoolLevelTags {
+		if tagSet[level] {
+			levels = append(levels, level)
+		}
+	}
+	return strings.Join(levels, ",")
+}
+
+func isBlankRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// rowParser 读取单行数据的各列，记录遇到的第一个错误
+type rowParser struct {
+	row     []string
+	columns map[string]int
+	err     *RowError
+}
+
+func (p *rowParser) fail(column, msg string) {
+	if p.err == nil {
+		p.err = &RowError{Field: column, Msg: msg}
+	}
+}
+
+func (p *rowParser) text(column string) string {
+	if i, ok := p.columns[column]; ok && i < len(p.row) {
+		return strings.TrimSpace(p.row[i])
+	}
+	return ""
+}
+
+func (p *rowParser) optionalText(column string) *string {
+	if value := p.text(column); value != "" {
+		return &value
+	}
+	return nil
+}
+
+func (p *rowParser) required(column string) string {
+	value := p.text(column)
+	if value == "" {
+		p.fail(column, "不能为空")
+	}
+	return value
+}
+
+// groupCode 专业组代码统一为两位数字，如 1 -> "01"
+func (p *rowParser) groupCode(column string) string {
+	value := p.required(column)
+	if value == "" {
+		return ""
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 || n != math.Trunc(n) {
+		p.fail(column, "不是有效的专业组代码: "+value)
+		return ""
+	}
+	return fmt.Sprintf("%02d", int(n))
+}
+
+// parseNumber 解析整数，Excel 中的数字可能带有为零的小数部分（如 "12.0"），其他小数不做截断
+func (p *rowParser) parseNumber(column string, maxValue int64) (int32, bool) {
+	value := p.text(column)
+	if value == "" || value == "-" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil {
+		p.fail(column, "不是有效的数字: "+value)
+		return 0, false
+	}
+	if n != math.Trunc(n) {
+		p.fail(column, "不是有效的整数: "+value)
+		return 0, false
+	}
+	if n < 0 || n > float64(maxValue) {
+		p.fail(column, fmt.Sprintf("超出范围 0~%d: %s", maxValue, value))
+		return 0, false
+	}
+	return int32(n), true
+}
+
+// number 解析整数，为空时返回 0
+func (p *rowParser) number(column string, maxValue int64) int32 {
+	n, _ := p.parseNumber(column, maxValue)
+	return n
+}
+
+// optionalNumber 解析整数，为空时返回 nil
+func (p *rowParser) optionalNumber(column string, maxValue int64) *int32 {
+	n, ok := p.parseNumber(column, maxValue)
+	if !ok {
+		return nil
+	}
+	return &n
+}
+
+// enum 映射枚举列，未知取值时拒绝该行
+func (p *rowParser) enum(column string, mapper func(string) (int, bool)) int8 {
+	value := p.text(column)
+	n, ok := mapper(value)
+	if !ok {
+		p.fail(column, fmt.Sprintf("未知取值: %q", value))
+		return 0
+	}
+	return int8(n)
+}
@@ -0,0 +1,74 @@
+package etl
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRowParserNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		maxValue int64
+		want     int32
+		wantNil  bool
+		wantErr  string
+	}{
+		{name: "整数", value: "615", maxValue: math.MaxUint16, want: 615},
+		{name: "去除空白", value: " 615 ", maxValue: math.MaxUint16, want: 615},
+		{name: "Excel 小数部分为零", value: "12.0", maxValue: math.MaxUint16, want: 12},
+		{name: "千分位", value: "12,345", maxValue: math.MaxInt32, want: 12345},
+		{name: "空单元格", value: "", maxValue: math.MaxUint16, wantNil: true},
+		{name: "横线表示无数据", value: "-", maxValue: math.MaxUint16, wantNil: true},
+		{name: "最大值", value: "255", maxValue: math.MaxUint8, want: 255},
+		{name: "小数不截断", value: "12.5", maxValue: math.MaxUint16, wantNil: true, wantErr: "不是有效的整数: 12.5"},
+		{name: "非数字", value: "abc", maxValue: math.MaxUint16, wantNil: true, wantErr: "不是有效的数字: abc"},
+		{name: "负数", value: "-1", maxValue: math.MaxUint16, wantNil: true, wantErr: "超出范围 0~65535: -1"},
+		{name: "超过最大值", value: "256", maxValue: math.MaxUint8, wantNil: true, wantErr: "超出范围 0~255: 256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns := map[string]int{"value": 0}
+
+			p := &rowParser{row: []string{tt.value}, columns: columns}
+			got := p.optionalNumber("value", tt.maxValue)
+			if (got == nil) != tt.wantNil || (got != nil && *got != tt.want) {
+				t.Errorf("optionalNumber(%q) = %v, want %d (nil %v)", tt.value, got, tt.want, tt.wantNil)
+			}
+			checkRowError(t, p, tt.wantErr)
+
+			p = &rowParser{row: []string{tt.value}, columns: columns}
+			want := tt.want
+			if tt.wantNil {
+				want = 0
+			}
+			if n := p.number("value", tt.maxValue); n != want {
+				t.Errorf("number(%q) = %d, want %d", tt.value, n, want)
+			}
+			checkRowError(t, p, tt.wantErr)
+		})
+	}
+}
+
+func TestRowParserNumberMissingColumn(t *testing.T) {
+	p := &rowParser{row: []string{"615"}, columns: map[string]int{"value": 3}}
+	if got := p.optionalNumber("value", math.MaxUint16); got != nil {
+		t.Errorf("optionalNumber() = %d, want nil", *got)
+	}
+	if got := p.number("other", math.MaxUint16); got != 0 {
+		t.Errorf("number() = %d, want 0", got)
+	}
+	checkRowError(t, p, "")
+}
+
+// checkRowError 校验解析器记录的错误消息，wantErr 为空表示没有错误
+func checkRowError(t *testing.T, p *rowParser, wantErr string) {
+	t.Helper()
+	switch {
+	case wantErr == "" && p.err != nil:
+		t.Errorf("unexpected row error: %v", p.err)
+	case wantErr != "" && (p.err == nil || p.err.Msg != wantErr || p.err.Field != "value"):
+		t.Errorf("row error = %v, want value: %s", p.err, wantErr)
+	}
+}
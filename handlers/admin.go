@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SetUserRole changes an account's role; counselor and admin roles can only be granted here
+// @Summary Set account role
+// @Description Change the role of an account identified by phone number or email. Admin only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SetUserRoleRequest true "Account and Role"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /api/admin/users/role [put]
+func SetUserRole(c *gin.Context) {
+	var request models.SetUserRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	user, err := models.SetUserRole(c.Request.Context(), request.Account, request.Role)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errcode.New(errcode.UserNotFound, request.Account)
+		}
+		respondError(c, err)
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "修改账号角色", "operatorID", currentUserID(c), "userID", user.ID, "role", user.Role)
+	respondOK(c, user, "角色修改成功")
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/models"
+	"gaokao-data-analysis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextUserIDKey 鉴权中间件写入 gin.Context 的当前用户ID键
+const ContextUserIDKey = "userID"
+
+// currentUserID 获取当前登录用户ID，匿名访问时返回空字符串
+func currentUserID(c *gin.Context) string {
+	return c.GetString(ContextUserIDKey)
+}
+
+// issueToken 签发令牌并构造响应
+func issueToken(c *gin.Context, user *models.User, msg string) {
+	token, expiresAt, err := utils.GenerateToken(user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondOK(c, models.AuthTokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		User:      user,
+	}, msg)
+}
+
+// Register handles account sign-up
+// @Summary Register a new account
+// @Description Sign up with a phone number or email and a password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.RegisterRequest true "Account Info"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /api/auth/register [post]
+func Register(c *gin.Context) {
+	var request models.RegisterRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+	if request.Phone == "" && request.Email == "" {
+		respondError(c, errcode.New(errcode.InvalidRequest, "必须提供手机号或邮箱"))
+		return
+	}
+
+	user, err := models.RegisterUser(c.Request.Context(), &request)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "账号注册成功", "userID", user.ID)
+	issueToken(c, user, "注册成功")
+}
+
+// Login handles account sign-in
+// @Summary Sign in
+// @Description Sign in with a phone number or email and a password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.LoginRequest true "Credentials"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /api/auth/login [post]
+func Login(c *gin.Context) {
+	var request models.LoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	user, err := models.AuthenticateUser(c.Request.Context(), request.Account, request.Password)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			slog.WarnContext(c.Request.Context(), "登录失败", "clientIP", c.ClientIP())
+		}
+		respondError(c, err)
+		return
+	}
+
+	issueToken(c, user, "登录成功")
+}
+
+// GetCurrentUser returns the signed-in account and its profiles
+// @Summary Get current account
+// @Description Get the signed-in account, the profiles it owns and the profiles it was added to as a counselor or parent
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Router /api/auth/me [get]
+func GetCurrentUser(c *gin.Context) {
+	user, err := models.GetUserByID(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		respondError(c, accountError(err))
+		return
+	}
+
+	profiles, err := models.ListUserProfilesByOwner(c.Request.Context(), user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	memberProfiles, err := models.ListMemberProfiles(c.Request.Context(), user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondOK(c, gin.H{
+		"user":            user,
+		"profiles":        profiles,
+		"member_profiles": memberProfiles,
+	}, "查询成功")
+}
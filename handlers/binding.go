@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/i18n"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// 校验错误中使用 json/form 标签中的字段名，与客户端提交的参数名一致
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, key := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// validationMessages 校验规则对应的消息模板，参数依次为字段名和规则参数
+var validationMessages = map[string]string{
+	"required": "%s 为必填项",
+	"min":      "%s 长度不能少于 %s",
+	"max":      "%s 长度不能超过 %s",
+	"gt":       "%s 必须大于 %s",
+	"oneof":    "%s 必须是以下值之一: %s",
+}
+
+// bindError 将请求绑定失败转换为参数错误，校验和解析错误按响应语言输出
+func bindError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		format := make([]string, len(validationErrs))
+		args := make([]interface{}, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			format[i] = "%s"
+			args[i] = fieldMessage(fieldErr)
+		}
+		return errcode.Newf(errcode.InvalidRequest, strings.Join(format, "; "), args...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return errcode.Newf(errcode.InvalidRequest, "%s 类型错误", typeErr.Field)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return errcode.New(errcode.InvalidRequest, "请求体不是有效的 JSON")
+	case errors.Is(err, io.EOF):
+		return errcode.New(errcode.InvalidRequest, "请求体为空")
+	}
+	return errcode.New(errcode.InvalidRequest, err.Error())
+}
+
+// fieldMessage 返回单个字段校验失败的消息，未登记的规则使用通用消息
+func fieldMessage(fieldErr validator.FieldError) i18n.Message {
+	// 去掉命名空间中的结构体类型名，嵌套字段保留路径，如 items[0].school_code
+	_, field, ok := strings.Cut(fieldErr.Namespace(), ".")
+	if !ok {
+		field = fieldErr.Field()
+	}
+	if format, exists := validationMessages[fieldErr.Tag()]; exists {
+		if fieldErr.Param() == "" {
+			return i18n.M(format, field)
+		}
+		return i18n.M(format, field, fieldErr.Param())
+	}
+	return i18n.M("%s 校验失败（%s）", field, fieldErr.Tag())
+}
@@ -1,40 +1,90 @@
 package handlers
 
-import "github.com/google/uuid"
+import (
+	"errors"
+	"net/http"
 
-type CommonInfoResponse struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg"`
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/i18n"
+	"gaokao-data-analysis/logs"
+	"gaokao-data-analysis/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// traceID 返回当前请求的 trace ID
+func traceID(c *gin.Context) string {
+	return logs.TraceID(c.Request.Context())
+}
+
+// lang 返回当前请求的响应语言，由语言中间件根据 Accept-Language 写入
+func lang(c *gin.Context) i18n.Lang {
+	return i18n.FromContext(c.Request.Context())
 }
 
-type CommonDataResponse struct {
-	Code int         `json:"code"`
-	Msg  string      `json:"msg"`
-	Data interface{} `json:"data"`
+// respondOK 返回成功响应，msg 为中文原文，按响应语言翻译
+func respondOK(c *gin.Context, data interface{}, msg string) {
+	c.JSON(http.StatusOK, models.SuccessResponse(data, i18n.T(lang(c), msg)))
 }
 
-func commonSucResp(data interface{}, msg string) *CommonDataResponse {
-	return &CommonDataResponse{
-		Code: 200,
-		Msg:  msg,
-		Data: data,
+// errorResp 将错误转换为带错误码和 trace ID 的错误响应，便于用户反馈的错误与日志关联
+// 服务器内部错误只返回错误码的默认消息，原始错误记录到日志
+func errorResp(c *gin.Context, err error) (int, *models.APIResponse) {
+	codeErr := errcode.From(err)
+	status := codeErr.Code.Status()
+	if status >= http.StatusInternalServerError {
+		logs.FromContext(c.Request.Context()).ErrorContext(c.Request.Context(), "请求处理失败",
+			"errorCode", codeErr.Code,
+			"error", err.Error(),
+			"path", c.FullPath(),
+		)
 	}
+
+	resp := models.ErrorResponse(codeErr, lang(c))
+	resp.TraceID = traceID(c)
+	return status, resp
+}
+
+// respondError 返回错误响应，所有处理函数的错误都通过它返回
+// 带错误码的错误使用目录中的状态码和消息，其余错误视为服务器内部错误
+func respondError(c *gin.Context, err error) {
+	c.JSON(errorResp(c, err))
+}
+
+// respondErrorWithData 返回错误响应并附带数据，用于健康检查等失败时仍需返回详细状态的接口
+func respondErrorWithData(c *gin.Context, err error, data interface{}) {
+	status, resp := errorResp(c, err)
+	resp.Data = data
+	c.JSON(status, resp)
 }
 
-func clientErrResp(code int, msg string) *CommonInfoResponse {
-	return &CommonInfoResponse{
-		Code: code,
-		Msg:  msg,
+// AbortWithError 中止请求并返回错误响应，供中间件使用
+func AbortWithError(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(errorResp(c, err))
+}
+
+// profileError 将档案查询错误转换为带错误码的错误，记录不存在时返回 ProfileNotFound
+func profileError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errcode.New(errcode.ProfileNotFound, "")
 	}
+	return err
 }
 
-func serverErrResp(msg string) *CommonInfoResponse {
-	return &CommonInfoResponse{
-		Code: 500,
-		Msg:  msg,
+// accountError 将账号查询错误转换为带错误码的错误，记录不存在时返回 AccountNotFound
+func accountError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errcode.New(errcode.AccountNotFound, "")
 	}
+	return err
 }
 
+// ContextTraceIDKey 请求日志中间件写入 gin.Context 的 trace ID 键
+const ContextTraceIDKey = "traceID"
+
+// GenerateTraceID 生成请求的 trace ID
 func GenerateTraceID() string {
 	return uuid.New().String()
 }
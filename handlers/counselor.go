@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetCounselorRoster returns the students managed by the signed-in counselor
+// @Summary Get counselor roster
+// @Description List the profiles a counselor manages with score, rank and form completion
+// @Tags counselor
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /api/counselor/roster [get]
+func GetCounselorRoster(c *gin.Context) {
+	user, err := models.GetUserByID(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		respondError(c, accountError(err))
+		return
+	}
+
+	roster, err := models.GetCounselorRoster(c.Request.Context(), user)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondOK(c, roster, "查询成功")
+}
+
+// ListProfileMembers lists the counselors and parents linked to a profile
+// @Summary List profile members
+// @Description List the counselors and parents who can access a profile
+// @Tags user-profiles
+// @Produce json
+// @Param id path string true "User Profile ID"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /api/profile/{id}/members [get]
+func ListProfileMembers(c *gin.Context) {
+	userProfile, ok := loadProfileWithAccess(c, c.Param("id"), models.ProfileAccessRead)
+	if !ok {
+		return
+	}
+
+	members, err := models.ListProfileMembers(c.Request.Context(), userProfile.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondOK(c, members, "查询成功")
+}
+
+// AddProfileMember grants a counselor or parent access to a profile
+// @Summary Add a profile member
+// @Description Grant a counselor read-write access or a parent read-only access to a profile.
+// @Description Only the owner, an admin or a counselor of the profile may add members; guest profiles must be claimed first
+// @Tags user-profiles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User Profile ID"
+// @Param request body models.AddProfileMemberRequest true "Member Info"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /api/profile/{id}/members [post]
+func AddProfileMember(c *gin.Context) {
+	var request models.AddProfileMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	userProfile, ok := loadProfileWithAccess(c, c.Param("id"), models.ProfileAccessWrite)
+	if !ok {
+		return
+	}
+
+	member, err := models.AddProfileMember(c.Request.Context(), userProfile, currentUserID(c), &request)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errcode.New(errcode.UserNotFound, request.Account)
+		}
+		respondError(c, err)
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "添加档案成员", "profileID", userProfile.ID, "memberID", member.UserID, "relation", member.Relation)
+	respondOK(c, member, "成员添加成功")
+}
+
+// RemoveProfileMember revokes a member's access to a profile
+// @Summary Remove a profile member
+// @Description Revoke a counselor's or parent's access to a profile.
+// @Description Only the owner, an admin or a counselor of the profile may remove members; guest profiles must be claimed first
+// @Tags user-profiles
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User Profile ID"
+// @Param userId path string true "Member User ID"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /api/profile/{id}/members/{userId} [delete]
+func RemoveProfileMember(c *gin.Context) {
+	userProfile, ok := loadProfileWithAccess(c, c.Param("id"), models.ProfileAccessWrite)
+	if !ok {
+		return
+	}
+
+	if err := models.RemoveProfileMember(c.Request.Context(), userProfile, currentUserID(c), c.Param("userId")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondOK(c, nil, "成员移除成功")
+}
@@ -1,22 +1,106 @@
 package handlers
 
 import (
-	"net/http"
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"gaokao-data-analysis/database"
+	"gaokao-data-analysis/errcode"
 
 	"github.com/gin-gonic/gin"
 )
 
+// healthCheckTimeout 健康检查中每个后端 ping 的超时时间
+const healthCheckTimeout = 2 * time.Second
+
+// shuttingDown 收到退出信号后置为 true，就绪检查随即返回 503
+var shuttingDown atomic.Bool
+
+// SetShuttingDown 标记服务正在关闭，负载均衡据此停止转发新请求
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
 // HealthCheck godoc
 // @Summary Show the status of server.
-// @Description get the status of server.
+// @Description Ping every storage backend and report latency and connection pool stats in data.
+// @Description data.status is "ok" when all backends are up, "degraded" when only ClickHouse is down
+// @Description (recommendation endpoints fail, profiles still work) and "unavailable" when the database is down,
+// @Description in which case the response is 503 with error_code SERVICE_UNAVAILABLE.
 // @Tags root
 // @Accept */*
 // @Produce json
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
 // @Router /health [get]
 func HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "ok",
-		"message": "Service is running",
-	})
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	dbHealth := database.CheckDatabaseHealth(ctx)
+	clickHouseHealth := database.CheckClickHouseHealth(ctx)
+
+	data := gin.H{
+		"status":            "ok",
+		"admission_backend": database.GetAdmissionBackend(),
+		"backends": gin.H{
+			"database":   dbHealth,
+			"clickhouse": clickHouseHealth,
+		},
+	}
+
+	switch {
+	case !database.IsReady() || dbHealth.Status != database.HealthStatusUp:
+		data["status"] = "unavailable"
+		respondErrorWithData(c, errcode.Wrap(errcode.Unavailable, errors.New("database is down: "+dbHealth.Error)), data)
+	case clickHouseHealth.Status == database.HealthStatusDown:
+		data["status"] = "degraded"
+		respondOK(c, data, "服务降级运行")
+	default:
+		respondOK(c, data, "服务正常")
+	}
+}
+
+// LivenessCheck godoc
+// @Summary Liveness probe
+// @Description Report that the process is running. Does not touch any backend.
+// @Tags root
+// @Produce json
+// @Success 200 {object} models.APIResponse
+// @Router /health/live [get]
+func LivenessCheck(c *gin.Context) {
+	respondOK(c, gin.H{"status": "ok"}, "服务运行中")
+}
+
+// ReadinessCheck godoc
+// @Summary Readiness probe
+// @Description Report whether the service can accept traffic, i.e. the database is initialized and reachable
+// @Description and the server is not shutting down. Returns 503 with error_code SERVICE_UNAVAILABLE otherwise.
+// @Tags root
+// @Produce json
+// @Success 200 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
+// @Router /health/ready [get]
+func ReadinessCheck(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	if shuttingDown.Load() {
+		respondError(c, errcode.New(errcode.Unavailable, "服务正在关闭"))
+		return
+	}
+
+	if !database.IsReady() {
+		respondError(c, errcode.New(errcode.Unavailable, "数据库尚未就绪"))
+		return
+	}
+
+	if dbHealth := database.CheckDatabaseHealth(ctx); dbHealth.Status != database.HealthStatusUp {
+		respondError(c, errcode.Wrap(errcode.Unavailable, errors.New("database is down: "+dbHealth.Error)))
+		return
+	}
+
+	respondOK(c, gin.H{"status": "ok"}, "服务正常")
 }
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,13 +16,9 @@ type ProvinceCity struct {
 	Cities   []string `json:"citys"`    // 对应的城市列表
 }
 
-// ProvinceOptionsResponse represents the response structure for province options
-type ProvinceOptionsResponse struct {
-	Code int    `json:"code"` // 响应码，0表示成功
-	Msg  string `json:"msg"`  // 响应消息
-	Data struct {
-		Provinces []ProvinceCity `json:"provinces"` // 可选省份列表
-	} `json:"data"` // 响应数据
+// ProvinceOptionsData represents the data part of province options response
+type ProvinceOptionsData struct {
+	Provinces []ProvinceCity `json:"provinces"` // 可选省份列表
 }
 
 var (
@@ -67,8 +62,7 @@ func loadProvinceCache() {
 // @Accept json
 // @Produce json
 // @Param province query string false "限制返回省份，支持多个省份用逗号分隔，如: 湖北,湖南"
-// @Success 200 {object} ProvinceOptionsResponse "成功返回省份列表"
-// @Failure 500 {object} gin.H "服务器内部错误"
+// @Success 200 {object} models.APIResponse{data=ProvinceOptionsData} "成功返回省份列表"
 // @Router /api/options/provinces [get]
 func GetProvinceOptions(c *gin.Context) {
 	// 确保数据已加载（只在首次调用时执行）
@@ -103,11 +97,5 @@ func GetProvinceOptions(c *gin.Context) {
 	}
 
 	// 构造并返回响应
-	c.JSON(http.StatusOK, ProvinceOptionsResponse{
-		Code: 0,
-		Msg:  "success",
-		Data: struct {
-			Provinces []ProvinceCity `json:"provinces"`
-		}{Provinces: result},
-	})
+	respondOK(c, ProvinceOptionsData{Provinces: result}, "查询成功")
 }
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize 导入文件大小上限
+const maxImportFileSize = 5 << 20
+
+// ImportUserProfiles bulk-imports student profiles from a spreadsheet
+// @Summary Import profiles
+// @Description Import profiles from a CSV or XLSX file (name, province, subjects, score, rank, preferences); invalid rows are reported individually
+// @Tags counselor
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Only validate rows without creating profiles"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 413 {object} models.APIResponse
+// @Router /api/counselor/import [post]
+func ImportUserProfiles(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, errcode.New(errcode.InvalidImportFile, err.Error()))
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		respondError(c, errcode.Newf(errcode.ImportFileTooLarge, "不能超过 %d MB", maxImportFileSize>>20))
+		return
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	if f := c.PostForm("format"); f != "" {
+		format = f
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, errcode.New(errcode.InvalidImportFile, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	records, err := models.ReadProfileImport(file, format)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	result, err := models.ImportUserProfiles(c.Request.Context(), records, currentUserID(c), dryRun)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "批量导入用户档案",
+		"userID", currentUserID(c),
+		"dryRun", dryRun,
+		"total", result.Total,
+		"created", len(result.Created),
+		"failed", len(result.Errors),
+	)
+	respondOK(c, result, "导入完成")
+}
+
+// ExportUserProfiles exports the managed profiles with their saved recommendations
+// @Summary Export profiles
+// @Description Export the counselor's profiles and their saved application forms as CSV or JSON
+// @Tags counselor
+// @Produce json,text/csv
+// @Security BearerAuth
+// @Param format query string false "Export format" Enums(csv,json) default(csv)
+// @Success 200 {array} models.ProfileExport
+// @Failure 400 {object} models.APIResponse
+// @Router /api/counselor/export [get]
+func ExportUserProfiles(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		respondError(c, errcode.New(errcode.UnsupportedFormat, format))
+		return
+	}
+
+	user, err := models.GetUserByID(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		respondError(c, accountError(err))
+		return
+	}
+
+	profiles, err := models.ListManagedProfiles(c.Request.Context(), user)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	exports, err := models.BuildProfileExports(c.Request.Context(), profiles)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=profiles."+format)
+	if format == "json" {
+		c.JSON(http.StatusOK, exports)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := models.WriteProfilesCSV(c.Writer, exports); err != nil {
+		slog.ErrorContext(c.Request.Context(), "写入导出文件失败", "error", err.Error(), "userID", user.ID)
+	}
+}
+
+// GetSavedRecommendations lists a profile's saved application form
+// @Summary Get saved recommendations
+// @Description List the saved application form (志愿表) of a profile in order
+// @Tags user-profiles
+// @Produce json
+// @Param id path string true "User Profile ID"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /api/profile/{id}/recommendations [get]
+func GetSavedRecommendations(c *gin.Context) {
+	userProfile, ok := loadProfileWithAccess(c, c.Param("id"), models.ProfileAccessRead)
+	if !ok {
+		return
+	}
+
+	recommendations, err := models.ListSavedRecommendations(c.Request.Context(), userProfile.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondOK(c, recommendations, "查询成功")
+}
+
+// SaveRecommendations replaces a profile's saved application form
+// @Summary Save recommendations
+// @Description Replace the saved application form (志愿表) of a profile; items are stored in request order
+// @Tags user-profiles
+// @Accept json
+// @Produce json
+// @Param id path string true "User Profile ID"
+// @Param request body models.SaveRecommendationsRequest true "Application form"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /api/profile/{id}/recommendations [put]
+func SaveRecommendations(c *gin.Context) {
+	var request models.SaveRecommendationsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	userProfile, ok := loadProfileWithAccess(c, c.Param("id"), models.ProfileAccessWrite)
+	if !ok {
+		return
+	}
+
+	recommendations, err := models.ReplaceSavedRecommendations(c.Request.Context(), userProfile.ID, request.Items)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondOK(c, recommendations, "志愿表保存成功")
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"log/slog"
+	"strconv"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateProfileSnapshot records a mock-exam score for a profile
+// @Summary Record a profile snapshot
+// @Description Record the score and rank of one exam (一模/二模/三模) for a profile
+// @Tags user-profiles
+// @Accept json
+// @Produce json
+// @Param id path string true "User Profile ID"
+// @Param request body models.ProfileSnapshotRequest true "Snapshot Info"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /api/profile/{id}/snapshots [post]
+func CreateProfileSnapshot(c *gin.Context) {
+	var request models.ProfileSnapshotRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	userProfile, ok := loadProfileWithAccess(c, c.Param("id"), models.ProfileAccessWrite)
+	if !ok {
+		return
+	}
+
+	snapshot, err := models.CreateProfileSnapshot(c.Request.Context(), userProfile, &request)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "档案快照保存成功", "profileID", userProfile.ID, "snapshotID", snapshot.ID, "label", snapshot.Label)
+	respondOK(c, snapshot, "快照保存成功")
+}
+
+// GetProfileTrend returns the score trend of a profile across exams
+// @Summary Get profile score trend
+// @Description List a profile's snapshots in exam order with the rank-equivalent of each score
+// @Tags user-profiles
+// @Produce json
+// @Param id path string true "User Profile ID"
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /api/profile/{id}/snapshots [get]
+func GetProfileTrend(c *gin.Context) {
+	userProfile, ok := loadProfileWithAccess(c, c.Param("id"), models.ProfileAccessRead)
+	if !ok {
+		return
+	}
+
+	trend, err := models.GetProfileTrend(c.Request.Context(), userProfile)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondOK(c, trend, "查询成功")
+}
+
+// DeleteProfileSnapshot deletes one snapshot of a profile
+// @Summary Delete a profile snapshot
+// @Description Delete one recorded exam snapshot of a profile
+// @Tags user-profiles
+// @Produce json
+// @Param id path string true "User Profile ID"
+// @Param snapshotId path int true "Snapshot ID"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /api/profile/{id}/snapshots/{snapshotId} [delete]
+func DeleteProfileSnapshot(c *gin.Context) {
+	snapshotID, err := strconv.ParseUint(c.Param("snapshotId"), 10, 64)
+	if err != nil {
+		respondError(c, errcode.New(errcode.InvalidRequest, "无效的快照 ID"))
+		return
+	}
+
+	userProfile, ok := loadProfileWithAccess(c, c.Param("id"), models.ProfileAccessWrite)
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteProfileSnapshot(c.Request.Context(), userProfile.ID, uint(snapshotID)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondOK(c, nil, "快照删除成功")
+}
@@ -1,9 +1,9 @@
 package handlers
 
 import (
+	"gaokao-data-analysis/errcode"
 	"gaokao-data-analysis/models"
 	"log/slog"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,29 +22,17 @@ import (
 func CreateUserProfile(c *gin.Context) {
 	var request models.UserProfileRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		slog.Warn("请求验证失败",
+		slog.WarnContext(c.Request.Context(), "请求验证失败",
 			"error", err.Error(),
 			"clientIP", c.ClientIP(),
 			"path", c.FullPath(),
 		)
-		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "Invalid request: "+err.Error()))
-		return
-	}
-
-	// Validate required fields
-	if request.Username == "" || request.Province == "" || len(request.Subjects) == 0 {
-		slog.Warn("缺少必要字段",
-			"username", request.Username != "",
-			"province", request.Province != "",
-			"subjects", len(request.Subjects),
-			"clientIP", c.ClientIP(),
-		)
-		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "Missing required fields: username, province, and subjects are required"))
+		respondError(c, bindError(err))
 		return
 	}
 
 	// 使用结构化日志记录用户请求信息（不包含敏感数据）
-	slog.Info("创建用户档案",
+	slog.InfoContext(c.Request.Context(), "创建用户档案",
 		"username", request.Username,
 		"province", request.Province,
 		"subjectCount", len(request.Subjects),
@@ -52,22 +40,25 @@ func CreateUserProfile(c *gin.Context) {
 	)
 
 	// Use model's method to create user profile
-	userProfile, err := models.CreateUserProfile(&request)
+	userProfile, err := models.CreateUserProfile(c.Request.Context(), &request, currentUserID(c))
 	if err != nil {
-		slog.Error("创建用户档案失败",
+		slog.WarnContext(c.Request.Context(), "创建用户档案失败",
 			"error", err.Error(),
 			"username", request.Username,
 			"province", request.Province,
 		)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "Failed to create user profile: "+err.Error()))
+		respondError(c, err)
 		return
 	}
 
-	slog.Info("用户档案创建成功", "profileID", userProfile.ID)
+	slog.InfoContext(c.Request.Context(), "用户档案创建成功", "profileID", userProfile.ID, "warnings", len(userProfile.Warnings))
 
 	// Return success response
-	response := models.SuccessResponse(models.ProfileIDResponse{ProfileID: &userProfile.ID}, "User profile created successfully")
-	c.JSON(http.StatusOK, response)
+	respondOK(c, models.ProfileIDResponse{
+		ProfileID:  &userProfile.ID,
+		ClaimToken: userProfile.ClaimToken,
+		Warnings:   userProfile.Warnings,
+	}, "档案创建成功")
 }
 
 // GetUserProfile handles retrieving a user profile by ID
@@ -77,23 +68,118 @@ func CreateUserProfile(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "User Profile ID"
-// @Success 200 {object} models.UserProfile
+// @Success 200 {object} models.APIResponse{data=models.UserProfile}
 // @Failure 404 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /api/user-profiles/{id} [get]
 func GetUserProfile(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "Missing profile ID"))
+		respondError(c, errcode.New(errcode.InvalidRequest, "缺少档案 ID"))
+		return
+	}
+
+	userProfile, ok := loadProfileWithAccess(c, id, models.ProfileAccessRead)
+	if !ok {
+		return
+	}
+
+	respondOK(c, userProfile, "查询成功")
+}
+
+// loadProfileWithAccess 加载档案并校验当前用户的访问级别，失败时写入错误响应
+func loadProfileWithAccess(c *gin.Context, id string, required models.ProfileAccess) (*models.UserProfile, bool) {
+	userProfile, err := models.GetUserProfileByID(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, profileError(err))
+		return nil, false
+	}
+
+	access, err := models.GetProfileAccess(c.Request.Context(), userProfile, currentUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+	if access < required {
+		respondError(c, models.ErrProfileForbidden)
+		return nil, false
+	}
+
+	return userProfile, true
+}
+
+// UpdateUserProfile handles updating an existing user profile
+// @Summary Update a user profile
+// @Description Validate and overwrite a user profile, filling a missing score or rank from the score-rank table
+// @Tags user-profiles
+// @Accept json
+// @Produce json
+// @Param id path string true "User Profile ID"
+// @Param request body models.UserProfileRequest true "User Profile Info"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /api/profile/{id} [put]
+func UpdateUserProfile(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		respondError(c, errcode.New(errcode.InvalidRequest, "缺少档案 ID"))
+		return
+	}
+
+	var request models.UserProfileRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		slog.WarnContext(c.Request.Context(), "请求验证失败",
+			"error", err.Error(),
+			"clientIP", c.ClientIP(),
+			"path", c.FullPath(),
+		)
+		respondError(c, bindError(err))
+		return
+	}
+
+	userProfile, err := models.UpdateUserProfile(c.Request.Context(), id, currentUserID(c), &request)
+	if err != nil {
+		respondError(c, profileError(err))
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "用户档案更新成功", "profileID", userProfile.ID, "warnings", len(userProfile.Warnings))
+
+	respondOK(c, models.ProfileIDResponse{
+		ProfileID: &userProfile.ID,
+		Warnings:  userProfile.Warnings,
+	}, "档案更新成功")
+}
+
+// ClaimUserProfile handles claiming a guest profile after sign-up
+// @Summary Claim a guest profile
+// @Description Attach a profile created in guest mode to the signed-in account
+// @Tags user-profiles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User Profile ID"
+// @Param request body models.ClaimProfileRequest true "Claim token returned when the guest profile was created"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /api/profile/{id}/claim [post]
+func ClaimUserProfile(c *gin.Context) {
+	var request models.ClaimProfileRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, bindError(err))
 		return
 	}
 
-	// Use model's method to get user profile
-	userProfile, err := models.GetUserProfileByID(id)
+	userProfile, err := models.ClaimUserProfile(c.Request.Context(), c.Param("id"), currentUserID(c), request.ClaimToken)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "User profile not found"))
+		respondError(c, profileError(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, userProfile)
+	slog.InfoContext(c.Request.Context(), "用户档案认领成功", "profileID", userProfile.ID, "userID", currentUserID(c))
+	respondOK(c, models.ProfileIDResponse{ProfileID: &userProfile.ID}, "档案认领成功")
 }
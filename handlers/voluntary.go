@@ -4,93 +4,42 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
+	"gaokao-data-analysis/errcode"
 	"gaokao-data-analysis/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 )
 
-// convertProvinceNameToPinyin 将省份中文名转换为拼音
-func convertProvinceNameToPinyin(provinceName string) string {
-	// 省份中文名到拼音的映射
-	provinceMap := map[string]string{
-		"湖北":  "hubei",
-		"湖南":  "hunan",
-		"河北":  "hebei",
-		"河南":  "henan",
-		"山东":  "shandong",
-		"山西":  "shanxi",
-		"陕西":  "shaanxi",
-		"四川":  "sichuan",
-		"江苏":  "jiangsu",
-		"江西":  "jiangxi",
-		"浙江":  "zhejiang",
-		"安徽":  "anhui",
-		"福建":  "fujian",
-		"广东":  "guangdong",
-		"广西":  "guangxi",
-		"海南":  "hainan",
-		"贵州":  "guizhou",
-		"云南":  "yunnan",
-		"西藏":  "xizang",
-		"青海":  "qinghai",
-		"甘肃":  "gansu",
-		"宁夏":  "ningxia",
-		"新疆":  "xinjiang",
-		"内蒙古": "neimenggu",
-		"辽宁":  "liaoning",
-		"吉林":  "jilin",
-		"黑龙江": "heilongjiang",
-		"北京":  "beijing",
-		"天津":  "tianjin",
-		"上海":  "shanghai",
-		"重庆":  "chongqing",
-	}
-
-	// 查找对应的拼音
-	if pinyin, exists := provinceMap[provinceName]; exists {
-		return pinyin
-	}
-
-	// 如果没找到，返回原字符串的小写形式
-	return strings.ToLower(provinceName)
-}
-
 // convertRankToScore 将位次转换为分数的辅助函数
 // province: 报考省份（例如：湖北 或 hubei）
 // subjects: 科目组合，用逗号分隔（例如：物理,化学 或 历史,地理）
 // rank: 位次
 // 返回：分数和错误信息
-func convertRankToScore(province, subjects string, rank int) (int, error) {
+func convertRankToScore(ctx context.Context, province, subjects string, rank int) (int, error) {
 	// 转换省份名称为拼音
-	provincePinyin := convertProvinceNameToPinyin(province)
+	provincePinyin := models.ConvertProvinceNameToPinyin(province)
 
 	// 解析科目组合，确定是物理类还是历史类
-	var category string
-	if strings.Contains(subjects, "物理") {
-		category = "physics"
-	} else if strings.Contains(subjects, "历史") {
-		category = "history"
-	} else {
+	category := models.ScoreRankCategory(subjects)
+	if category == "" {
 		return 0, fmt.Errorf("无法确定科目类别，subjects: %s", subjects)
 	}
 
-	// 使用2024年数据
-	year := 2024
+	// 使用一分一段表年份数据
+	year := models.ScoreRankYear
 
 	// 调用核心查询函数
-	slog.Info("转换位次到分数",
+	slog.InfoContext(ctx, "转换位次到分数",
 		"province", provincePinyin,
 		"category", category,
 		"year", year,
 		"rank", rank,
 	)
-	score, err := QueryScoreByRank(provincePinyin, category, year, rank)
+	score, err := models.QueryScoreByRank(ctx, provincePinyin, category, year, rank)
 	if err != nil {
 		return 0, fmt.Errorf("查询分数失败: %v", err)
 	}
@@ -100,13 +49,16 @@ func convertRankToScore(province, subjects string, rank int) (int, error) {
 
 // UniversityPriorityVoluntary godoc
 // @Summary 查询志愿-院校优先
-// @Description 根据用户条件查询志愿-院校优先推荐
+// @Description 根据用户条件查询志愿-院校优先推荐，指定 profile_id 时需要对档案有读权限
 // @Tags voluntary
 // @Accept json,multipart/form-data,x-www-form-urlencoded
 // @Produce json
 // @Param request body models.VoluntaryUniversityPriorityRequest true "查询条件"
-// @Success 200 {object} models.VoluntaryUniversityPriorityResponse
+// @Success 200 {object} models.APIResponse
+// @Security BearerAuth
 // @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /api/voluntary/universityPriority [post]
 func UniversityPriorityVoluntary(c *gin.Context) {
@@ -129,23 +81,24 @@ func UniversityPriorityVoluntary(c *gin.Context) {
 	}
 
 	if err != nil {
-		slog.Warn("解析请求失败[志愿-院校优先]",
+		slog.WarnContext(c.Request.Context(), "解析请求失败[志愿-院校优先]",
 			"error", err.Error(),
 			"clientIP", c.ClientIP(),
 			"path", c.FullPath(),
 			"contentType", contentType,
 		)
-		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "无效的请求: "+err.Error()))
+		respondError(c, bindError(err))
 		return
 	}
 
 	// 处理特殊的表单字段转换，表单可能会将整数作为字符串提交
 	handleFormFieldConversions(c, &request)
+	request.UserID = currentUserID(c)
 
 	// 校验参数：必须有 profile_id，或者 (province, subjects, rank) 都有
 	if request.ProfileID == "" &&
 		(request.Province == "" || request.Subjects == "" || request.Rank == 0) {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "参数错误: 必须提供 profile_id 或 (province, subjects, rank)"))
+		respondError(c, errcode.New(errcode.InvalidRequest, "必须提供 profile_id 或 (province, subjects, rank)"))
 		return
 	}
 
@@ -160,7 +113,7 @@ func UniversityPriorityVoluntary(c *gin.Context) {
 	}
 
 	// 记录请求信息
-	slog.Info("接收到志愿-院校优先查询请求",
+	slog.InfoContext(c.Request.Context(), "接收到志愿-院校优先查询请求",
 		"profileID", request.ProfileID,
 		"province", request.Province,
 		"subjects", request.Subjects,
@@ -179,20 +132,16 @@ func UniversityPriorityVoluntary(c *gin.Context) {
 	// 调用模型层查询数据
 	data, err := models.GetUniversityPriorityVoluntary(ctx, &request)
 	if err != nil {
-		slog.Error("查询志愿-院校优先失败",
-			"error", err.Error(),
-			"profileID", request.ProfileID,
-		)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "查询失败: "+err.Error()))
+		respondError(c, err)
 		return
 	}
 
-	resp := commonSucResp(data, "查询成功")
-	c.JSON(http.StatusOK, resp)
+	respondOK(c, data, "查询成功")
 }
 
+// MajorPriorityVoluntary 志愿-专业优先，尚未实现
 func MajorPriorityVoluntary(c *gin.Context) {
-	c.JSON(500, serverErrResp("not implement this method"))
+	respondError(c, errcode.New(errcode.NotImplemented, ""))
 }
 
 // handleFormFieldConversions 处理表单字段的特殊转换
@@ -205,9 +154,9 @@ func handleFormFieldConversions(c *gin.Context, request *models.VoluntaryUnivers
 		}
 	}
 
-	score, err := convertRankToScore(request.Province, request.Subjects, int(request.Rank))
+	score, err := convertRankToScore(c.Request.Context(), request.Province, request.Subjects, int(request.Rank))
 	if err != nil {
-		slog.Warn("转换位次到分数失败[志愿-院校优先]",
+		slog.WarnContext(c.Request.Context(), "转换位次到分数失败[志愿-院校优先]",
 			"error", err.Error(),
 		)
 	}
@@ -237,13 +186,16 @@ func handleFormFieldConversions(c *gin.Context, request *models.VoluntaryUnivers
 
 // GetMajorGroupDetailsHandler godoc
 // @Summary 查询专业组详情
-// @Description 根据用户条件查询专业组详情
+// @Description 根据用户条件查询专业组详情，指定 profile_id 时需要对档案有读权限
 // @Tags voluntary
 // @Accept json,multipart/form-data,x-www-form-urlencoded
 // @Produce json
 // @Param request body models.VoluntaryMajorGroupRequest true "查询条件"
-// @Success 200 {object} models.VoluntaryMajorGroupResponse
+// @Success 200 {object} models.APIResponse{data=models.VoluntaryMajorGroup}
+// @Security BearerAuth
 // @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /api/voluntary/majorGroupDetails [post]
 func GetMajorGroupDetailsHandler(c *gin.Context) {
@@ -265,16 +217,18 @@ func GetMajorGroupDetailsHandler(c *gin.Context) {
 	}
 
 	if err != nil {
-		slog.Warn("解析请求失败[专业组详情]",
+		slog.WarnContext(c.Request.Context(), "解析请求失败[专业组详情]",
 			"error", err.Error(),
 			"clientIP", c.ClientIP(),
 			"path", c.FullPath(),
 			"contentType", contentType,
 		)
-		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "无效的请求: "+err.Error()))
+		respondError(c, bindError(err))
 		return
 	}
 
+	request.UserID = currentUserID(c)
+
 	// 处理特殊的表单字段转换
 	if scoreStr := c.PostForm("score"); scoreStr != "" && request.Score == 0 {
 		if score, err := strconv.Atoi(scoreStr); err == nil {
@@ -286,17 +240,28 @@ func GetMajorGroupDetailsHandler(c *gin.Context) {
 			request.Rank = int32(rank)
 		}
 	}
-	if strategyStr := c.PostForm("strategy"); strategyStr != "" && request.Strategy == 0 {
+	if strategyStr := c.PostForm("strategy"); strategyStr != "" && request.Strategy == nil {
 		if strategy, err := strconv.Atoi(strategyStr); err == nil {
-			request.Strategy = int32(strategy)
+			value := int32(strategy)
+			request.Strategy = &value
 		}
 	}
 
 	// 校验参数
 	if request.SchoolCode == "" || request.GroupCode == "" {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "参数错误: 必须提供school_code和group_code"))
+		respondError(c, errcode.New(errcode.InvalidRequest, "必须提供 school_code 和 group_code"))
 		return
 	}
+	strategies := request.Strategies
+	if request.Strategy != nil {
+		strategies = append(strategies, *request.Strategy)
+	}
+	for _, strategy := range strategies {
+		if strategy < 0 || strategy > 2 {
+			respondError(c, errcode.Newf(errcode.InvalidStrategy, "无效的策略: %d", strategy))
+			return
+		}
+	}
 
 	// 设置查询超时
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
@@ -305,15 +270,9 @@ func GetMajorGroupDetailsHandler(c *gin.Context) {
 	// 调用查询函数
 	majorGroup, err := models.GetMajorGroupDetail(ctx, &request)
 	if err != nil {
-		slog.Error("获取专业组详情失败",
-			"error", err.Error(),
-			"schoolCode", request.SchoolCode,
-			"groupCode", request.GroupCode,
-		)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "查询失败: "+err.Error()))
+		respondError(c, err)
 		return
 	}
 
-	resp := commonSucResp(majorGroup, "查询成功")
-	c.JSON(http.StatusOK, resp)
+	respondOK(c, majorGroup, "查询成功")
 }
@@ -0,0 +1,101 @@
+package i18n
+
+// english 英文译文，键为中文原文；新增面向用户的消息时需在此登记译文
+var english = map[string]string{
+	// 错误码默认消息
+	"请求参数错误":               "Invalid request",
+	"接口尚未实现":               "Not implemented",
+	"请求过于频繁，请稍后再试":         "Too many requests, please try again later",
+	"查询超时，请稍后重试":           "Query timed out, please try again later",
+	"服务器内部错误":              "Internal server error",
+	"服务暂不可用，请稍后重试":         "Service is temporarily unavailable, please try again later",
+	"请先登录":                 "Please sign in first",
+	"登录已失效，请重新登录":          "Session expired, please sign in again",
+	"账号或密码错误":              "Invalid account or password",
+	"账号不存在":                "Account not found",
+	"账号已存在":                "Account already exists",
+	"没有权限执行该操作":            "You do not have permission to perform this action",
+	"用户不存在":                "User not found",
+	"档案不存在":                "Profile not found",
+	"没有权限访问该档案":            "You do not have permission to access this profile",
+	"档案信息有误":               "Invalid profile",
+	"成员信息有误":               "Invalid member",
+	"导入文件有误":               "Invalid import file",
+	"导入文件过大":               "Import file is too large",
+	"不支持的文件格式":             "Unsupported file format",
+	"科目组合有误":               "Invalid subject combination",
+	"策略参数有误，可选值为 0冲、1稳、2保": "Invalid strategy, allowed values are 0 (reach), 1 (match), 2 (safety)",
+	"未找到对应的一分一段数据":         "Score-rank data not found",
+	"录取数据暂不可用，请稍后重试":       "Admission data is temporarily unavailable, please try again later",
+
+	// 成功消息
+	"查询成功":    "Query succeeded",
+	"注册成功":    "Registered successfully",
+	"登录成功":    "Signed in successfully",
+	"档案创建成功":  "Profile created successfully",
+	"档案更新成功":  "Profile updated successfully",
+	"档案认领成功":  "Profile claimed successfully",
+	"成员添加成功":  "Member added successfully",
+	"成员移除成功":  "Member removed successfully",
+	"快照保存成功":  "Snapshot saved successfully",
+	"快照删除成功":  "Snapshot deleted successfully",
+	"志愿表保存成功": "Recommendations saved successfully",
+	"导入完成":    "Import finished",
+	"服务正常":    "Service is healthy",
+	"服务降级运行":  "Service is degraded",
+	"服务运行中":   "Service is running",
+	"角色修改成功":  "Role updated successfully",
+
+	// 请求参数
+	"%s 为必填项":                                      "%s is required",
+	"%s 长度不能少于 %s":                                 "%s must be at least %s characters long",
+	"%s 长度不能超过 %s":                                 "%s must be at most %s characters long",
+	"%s 必须大于 %s":                                   "%s must be greater than %s",
+	"%s 必须是以下值之一: %s":                              "%s must be one of: %s",
+	"%s 校验失败（%s）":                                  "%s failed validation (%s)",
+	"%s 类型错误":                                      "%s has an invalid type",
+	"请求体不是有效的 JSON":                                "request body is not valid JSON",
+	"请求体为空":                                        "request body is empty",
+	"缺少档案 ID":                                      "missing profile ID",
+	"快照不存在":                                        "snapshot not found",
+	"无效的快照 ID":                                     "invalid snapshot ID",
+	"必须提供手机号或邮箱":                                   "phone or email is required",
+	"必须提供 profile_id 或 (province, subjects, rank)": "either profile_id or (province, subjects, rank) is required",
+	"必须提供 school_code 和 group_code":                "school_code and group_code are required",
+	"无效的策略: %d":                                    "invalid strategy: %d",
+	"无效的角色: %s":                                    "invalid role: %s",
+	"服务正在关闭":                                       "server is shutting down",
+	"数据库尚未就绪":                                      "database is not ready",
+	"%d 秒后可重试":                                     "retry after %d seconds",
+	"位次必须大于0":                                      "rank must be greater than 0",
+	"分数必须大于0":                                      "score must be greater than 0",
+	"类别参数错误，只支持 physics 或 history":                 "invalid category, only physics or history is supported",
+	"位次 %d":                                        "rank %d",
+	"分数 %d":                                        "score %d",
+
+	// 档案与成员
+	"不能为空":                  "must not be empty",
+	"分数不能为负数":               "score must not be negative",
+	"位次不能为负数":               "rank must not be negative",
+	"分数和位次至少需要提供一项":         "either score or rank is required",
+	"无法根据分数推算位次，请填写位次":      "cannot derive rank from score, please fill in the rank",
+	"无法根据位次推算分数，请填写分数":      "cannot derive score from rank, please fill in the score",
+	"日期格式应为 YYYY-MM-DD":     "date must be in YYYY-MM-DD format",
+	"科目不能为空":                "subjects must not be empty",
+	"科目组合必须包含物理或历史":         "subjects must include physics or history",
+	"缺少一分一段数据，未校验分数与位次是否一致": "Score-rank data is missing, score and rank were not cross-checked",
+	"位次 %d 对应分数约为 %d，与填写的分数 %d 相差 %d 分，请核对": "Rank %d corresponds to a score of about %d, which differs from the entered score %d by %d points, please check",
+	"该账号不是规划师":         "the account is not a counselor",
+	"该账号不是家长":          "the account is not a parent",
+	"档案尚未认领，认领后才能管理成员": "the profile has not been claimed yet, members can be managed after it is claimed",
+
+	// 导入导出
+	"不能超过 %d MB":   "must not exceed %d MB",
+	"解析CSV失败: %v":  "failed to parse CSV: %v",
+	"解析XLSX失败: %v": "failed to parse XLSX: %v",
+	"XLSX文件没有工作表":  "the XLSX file has no sheets",
+	"导入文件为空":       "the import file is empty",
+	"缺少必需列: %s":    "missing required column: %s",
+	"不是有效的整数: %s":  "not a valid integer: %s",
+	"超出取值范围: %s":   "out of range: %s",
+}
@@ -0,0 +1,116 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang API 消息使用的语言
+type Lang string
+
+// 支持的语言
+const (
+	Chinese Lang = "zh"
+	English Lang = "en"
+)
+
+// Default 默认语言，消息目录以中文原文作为键
+const Default = Chinese
+
+// translations 各语言的译文，键为中文原文；中文直接使用原文
+var translations = map[Lang]map[string]string{
+	English: english,
+}
+
+type langContextKey struct{}
+
+// WithLang 将语言写入 context
+func WithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, langContextKey{}, lang)
+}
+
+// FromContext 返回 context 中的语言，没有时返回默认语言
+func FromContext(ctx context.Context) Lang {
+	if ctx != nil {
+		if lang, ok := ctx.Value(langContextKey{}).(Lang); ok {
+			return lang
+		}
+	}
+	return Default
+}
+
+// Parse 按 Accept-Language 请求头选择语言，如 "en-US,en;q=0.9,zh;q=0.8"
+// 按权重从高到低取第一个支持的语言，都不支持时返回默认语言
+func Parse(header string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if lang := Lang(base); q > 0 && (lang == Chinese || lang == English) {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return Default
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// Localizable 由需要按语言输出的消息实现
+type Localizable interface {
+	Localize(lang Lang) string
+}
+
+// Message 延迟翻译的消息，在确定响应语言后再格式化
+type Message struct {
+	Key  string
+	Args []interface{}
+}
+
+// M 创建延迟翻译的消息，key 为中文原文或格式化模板
+func M(key string, args ...interface{}) Message {
+	return Message{Key: key, Args: args}
+}
+
+// Localize 实现 Localizable
+func (m Message) Localize(lang Lang) string {
+	return T(lang, m.Key, m.Args...)
+}
+
+// T 翻译消息并格式化参数，参数中的 Localizable 同样按该语言翻译
+// 没有译文时使用原文，因此未登记的消息（如第三方库的错误）原样返回
+func T(lang Lang, key string, args ...interface{}) string {
+	msg := key
+	if translated, ok := translations[lang][key]; ok {
+		msg = translated
+	}
+	if len(args) == 0 {
+		return msg
+	}
+
+	localized := make([]interface{}, len(args))
+	for i, arg := range args {
+		if l, ok := arg.(Localizable); ok {
+			arg = l.Localize(lang)
+		}
+		localized[i] = arg
+	}
+	return fmt.Sprintf(msg, localized...)
+}
@@ -0,0 +1,75 @@
+package logs
+
+import (
+	"context"
+	"log/slog"
+)
+
+// TraceIDKey 日志中 trace ID 的字段名
+const TraceIDKey = "trace_id"
+
+type traceIDContextKey struct{}
+
+type loggerContextKey struct{}
+
+// WithTraceID 将 trace ID 写入 context
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey{}, traceID)
+}
+
+// TraceID 返回 context 中的 trace ID，没有时返回空字符串
+func TraceID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	traceID, _ := ctx.Value(traceIDContextKey{}).(string)
+	return traceID
+}
+
+// WithLogger 将请求范围的记录器写入 context
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext 返回 context 中的请求范围记录器，没有时返回带 trace_id 的默认记录器
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	if traceID := TraceID(ctx); traceID != "" {
+		return slog.Default().With(TraceIDKey, traceID)
+	}
+	return slog.Default()
+}
+
+// contextHandler 为使用 *Context 方法记录的日志（如 slog.InfoContext、GORM 日志）补充 trace_id
+type contextHandler struct {
+	slog.Handler
+	// hasTraceID 记录器已通过 With 带上 trace_id 时不再重复添加
+	hasTraceID bool
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !h.hasTraceID {
+		if traceID := TraceID(ctx); traceID != "" {
+			record.AddAttrs(slog.String(TraceIDKey, traceID))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hasTraceID := h.hasTraceID
+	for _, attr := range attrs {
+		if attr.Key == TraceIDKey {
+			hasTraceID = true
+		}
+	}
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), hasTraceID: hasTraceID}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), hasTraceID: h.hasTraceID}
+}
@@ -0,0 +1,179 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 日志格式
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config 日志配置
+type Config struct {
+	// Level 日志级别: debug, info, warn, error
+	Level slog.Level `yaml:"level" env:"LOG_LEVEL"`
+	// Format 输出格式: json, text
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+	// OutputPath 日志文件路径，为空时只输出到标准输出
+	OutputPath string `yaml:"output_path" env:"LOG_OUTPUT_PATH"`
+	// Stdout 写入文件时是否同时输出到标准输出
+	Stdout bool `yaml:"stdout" env:"LOG_STDOUT"`
+	// AddSource 是否记录调用位置
+	AddSource bool `yaml:"add_source" env:"LOG_ADD_SOURCE"`
+
+	// 日志文件切分设置
+	// RotateInterval 按时间切分的周期: hourly, daily, none
+	RotateInterval string `yaml:"rotate_interval" env:"LOG_ROTATE_INTERVAL"`
+	// MaxSizeMB 单个文件的最大大小，单位 MB
+	MaxSizeMB int `yaml:"max_size_mb" env:"LOG_MAX_SIZE_MB"`
+	// MaxBackups 保留的历史文件数
+	MaxBackups int `yaml:"max_backups" env:"LOG_MAX_BACKUPS"`
+	// MaxAge 历史文件的最长保留时间
+	MaxAge time.Duration `yaml:"max_age" env:"LOG_MAX_AGE_DAYS" unit:"d"`
+}
+
+// DefaultConfig 返回默认日志配置
+func DefaultConfig() Config {
+	return Config{
+		Level:          slog.LevelInfo,
+		Format:         FormatText,
+		Stdout:         true,
+		RotateInterval: "daily",
+		MaxSizeMB:      100,
+		MaxBackups:     7,
+		MaxAge:         30 * 24 * time.Hour,
+	}
+}
+
+// Validate 校验日志配置
+func (cfg *Config) Validate() error {
+	var errs []error
+	switch strings.ToLower(cfg.Format) {
+	case FormatJSON, FormatText:
+	default:
+		errs = append(errs, fmt.Errorf("LOG_FORMAT 无效: %s，可选值为 json, text", cfg.Format))
+	}
+	if _, err := cfg.rotateInterval(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.MaxSizeMB < 0 || cfg.MaxBackups < 0 || cfg.MaxAge < 0 {
+		errs = append(errs, errors.New("LOG_MAX_SIZE_MB, LOG_MAX_BACKUPS 和 LOG_MAX_AGE_DAYS 不能为负数"))
+	}
+	return errors.Join(errs...)
+}
+
+// rotateInterval 解析按时间切分的周期
+func (cfg *Config) rotateInterval() (time.Duration, error) {
+	switch interval := strings.ToLower(cfg.RotateInterval); interval {
+	case "hourly":
+		return time.Hour, nil
+	case "daily":
+		return 24 * time.Hour, nil
+	case "none", "":
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("LOG_ROTATE_INTERVAL 无效: %s，可选值为 hourly, daily, none", interval)
+	}
+}
+
+// RotateOptions 返回日志文件切分设置
+func (cfg *Config) RotateOptions() (RotateOptions, error) {
+	interval, err := cfg.rotateInterval()
+	if err != nil {
+		return RotateOptions{}, err
+	}
+	return RotateOptions{
+		MaxSize:    int64(cfg.MaxSizeMB) << 20,
+		Interval:   interval,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAge,
+	}, nil
+}
+
+var (
+	mu   sync.Mutex
+	file *RotatingFile
+)
+
+// Setup 根据配置创建日志处理器并设置为默认记录器，可重复调用
+func Setup(cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	rotate, err := cfg.RotateOptions()
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var (
+		output  io.Writer = os.Stdout
+		newFile *RotatingFile
+	)
+	if cfg.OutputPath != "" {
+		newFile, err = OpenRotatingFile(cfg.OutputPath, rotate)
+		if err != nil {
+			return fmt.Errorf("打开日志文件失败: %w", err)
+		}
+		output = newFile
+		if cfg.Stdout {
+			output = io.MultiWriter(os.Stdout, newFile)
+		}
+	}
+
+	slog.SetDefault(slog.New(NewHandler(cfg, output)))
+
+	// 替换后再关闭旧文件，避免丢失切换期间的日志
+	if file != nil {
+		file.Close()
+	}
+	file = newFile
+
+	slog.Info("日志系统初始化完成",
+		"level", cfg.Level.String(),
+		"format", cfg.Format,
+		"output", cfg.OutputPath,
+	)
+	return nil
+}
+
+// NewHandler 创建 JSON 或文本格式的日志处理器，记录中会带上 context 中的 trace_id
+func NewHandler(cfg *Config, w io.Writer) slog.Handler {
+	opts := &slog.HandlerOptions{
+		Level:     cfg.Level,
+		AddSource: cfg.AddSource,
+	}
+
+	var handler slog.Handler
+	if strings.ToLower(cfg.Format) == FormatJSON {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return &contextHandler{Handler: handler}
+}
+
+// Close 关闭日志文件，之后的日志只输出到标准输出
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if file == nil {
+		return nil
+	}
+	slog.SetDefault(slog.New(&contextHandler{Handler: slog.NewTextHandler(os.Stdout, nil)}))
+	err := file.Close()
+	file = nil
+	return err
+}
@@ -0,0 +1,176 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat 切分后的文件名中的时间格式，如 app-20250618T000000.000.log
+const backupTimeFormat = "20060102T150405.000"
+
+// RotateOptions 日志文件切分设置，零值表示不限制
+type RotateOptions struct {
+	// MaxSize 单个文件的最大字节数
+	MaxSize int64
+	// Interval 按时间切分的周期，24 小时及以上按本地日期切分
+	Interval time.Duration
+	// MaxBackups 保留的历史文件数
+	MaxBackups int
+	// MaxAge 历史文件的最长保留时间
+	MaxAge time.Duration
+}
+
+// RotatingFile 按大小和时间切分的日志文件，可并发写入
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	opts     RotateOptions
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// OpenRotatingFile 打开（或创建）日志文件，目录不存在时自动创建
+func OpenRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	f := &RotatingFile{path: path, opts: opts}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write 实现 io.Writer，写入前检查是否需要切分
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.shouldRotate(int64(len(p)), time.Now()) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close 关闭日志文件
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	// 已有文件按其修改时间计算周期，跨周期重启时首次写入即切分
+	f.openedAt = time.Now()
+	if f.size > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (f *RotatingFile) shouldRotate(writeSize int64, now time.Time) bool {
+	if f.opts.MaxSize > 0 && f.size > 0 && f.size+writeSize > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.Interval > 0 && !f.periodStart(now).Equal(f.periodStart(f.openedAt))
+}
+
+// periodStart 返回时间所在切分周期的起点
+func (f *RotatingFile) periodStart(t time.Time) time.Time {
+	if f.opts.Interval >= 24*time.Hour {
+		year, month, day := t.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+	return t.Truncate(f.opts.Interval)
+}
+
+// rotate 将当前文件重命名为带时间戳的历史文件，并打开新文件
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	ext := filepath.Ext(f.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), time.Now().Format(backupTimeFormat), ext)
+	if err := os.Rename(f.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.removeOldBackups()
+	return nil
+}
+
+// removeOldBackups 删除超出保留数量或保留时间的历史文件
+func (f *RotatingFile) removeOldBackups() {
+	if f.opts.MaxBackups <= 0 && f.opts.MaxAge <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(f.path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return
+	}
+
+	// 只处理由切分生成的文件
+	var backups []string
+	for _, match := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	// 文件名中的时间戳可按字典序排序，从新到旧
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	cutoff := time.Now().Add(-f.opts.MaxAge)
+	for i, backup := range backups {
+		expired := f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups
+		if !expired && f.opts.MaxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && info.ModTime().Before(cutoff) {
+				expired = true
+			}
+		}
+		if expired {
+			os.Remove(backup)
+		}
+	}
+}
@@ -1,37 +1,155 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"gaokao-data-analysis/cmd"
 	"gaokao-data-analysis/config"
+	"gaokao-data-analysis/database"
+	"gaokao-data-analysis/handlers"
+	"gaokao-data-analysis/logs"
 	routes "gaokao-data-analysis/router"
-	"gaokao-data-analysis/utils"
+	"gaokao-data-analysis/telemetry"
 )
 
 func main() {
-	// 初始化配置和数据库连接
-	if err := config.InitConfig(); err != nil {
+	// 子命令：不带参数、serve 或直接跟配置参数（如 -config app.yaml）时启动服务
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "serve" {
+		args = args[1:]
+	} else if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if err := runCommand(args[0], args[1:]); err != nil {
+			slog.Error("命令执行失败", "command", args[0], "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 加载配置，配置无效时列出所有问题后退出
+	cfg, err := config.Load("serve", args)
+	if err != nil {
+		slog.Error("加载配置失败", "error", err)
+		os.Exit(1)
+	}
+
+	// 初始化日志、数据库连接和各模块配置
+	if err := config.InitConfig(cfg); err != nil {
 		slog.Error("初始化配置失败", "error", err)
 		os.Exit(1)
 	}
 
+	// 初始化链路追踪，导出方式为 none 时不导出
+	if err := telemetry.Setup(context.Background(), &cfg.Telemetry); err != nil {
+		slog.Error("初始化链路追踪失败", "error", err)
+		os.Exit(1)
+	}
+
 	// 记录应用程序启动信息
-	version := utils.GetEnv("APP_VERSION", "unknown")
 	slog.Info("应用程序初始化完成",
-		"version", version,
-		"environment", utils.GetEnv("GIN_MODE", "release"),
+		"version", cfg.Telemetry.ServiceVersion,
+		"environment", cfg.Server.Mode,
 	)
 
-	// 设置路由
-	r := routes.SetupRouter()
+	if err := serve(cfg); err != nil {
+		slog.Error("服务器运行失败", "error", err)
+		os.Exit(1)
+	}
+}
 
-	// 获取端口配置
-	port := utils.GetEnv("PORT", "8080")
+// serve 启动 HTTP 服务，收到 SIGINT/SIGTERM 后等待进行中的请求完成，再关闭数据库连接等资源
+func serve(cfg *config.Config) error {
+	r, limiter := routes.SetupRouter(cfg)
+	srv := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Server.Port),
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
 
-	slog.Info("启动服务器", "port", port)
-	if err := r.Run(":" + port); err != nil {
-		slog.Error("服务器启动失败", "error", err)
-		os.Exit(1)
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("启动服务器", "port", cfg.Server.Port, "tls", cfg.Server.TLSEnabled())
+		if cfg.Server.TLSEnabled() {
+			serveErr <- srv.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		// 监听失败（如端口被占用），无需等待请求完成
+		err = fmt.Errorf("服务器启动失败: %w", err)
+	case <-ctx.Done():
+		// 恢复默认信号处理，再次收到信号时立即退出
+		stop()
+		slog.Info("收到退出信号，开始关闭服务器",
+			"delay", cfg.Server.ShutdownDelay.String(),
+			"timeout", cfg.Server.ShutdownTimeout.String(),
+		)
+
+		// 就绪检查先返回 503，等待负载均衡摘除实例，期间仍正常处理请求
+		handlers.SetShuttingDown()
+		time.Sleep(cfg.Server.ShutdownDelay)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err = srv.Shutdown(shutdownCtx); err != nil {
+			err = fmt.Errorf("等待进行中的请求超时: %w", err)
+		} else {
+			slog.Info("进行中的请求已全部完成")
+		}
+	}
+
+	shutdown(limiter)
+	return err
+}
+
+// shutdown 依次关闭限流存储、数据库连接、链路追踪和日志文件，单项失败不影响其余资源的关闭
+func shutdown(limiter *routes.RateLimiter) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := limiter.Close(); err != nil {
+		slog.Warn("关闭限流存储失败", "error", err)
+	}
+	if err := database.Close(); err != nil {
+		slog.Warn("关闭数据库连接失败", "error", err)
+	}
+	if err := telemetry.Shutdown(ctx); err != nil {
+		slog.Warn("导出剩余链路数据失败", "error", err)
+	}
+	slog.Info("服务器已关闭")
+	logs.Close()
+}
+
+// runCommand 执行子命令
+func runCommand(name string, args []string) error {
+	switch name {
+	case "migrate":
+		return cmd.RunMigrate(args)
+	case "etl":
+		return cmd.RunETL(args)
+	case "config":
+		return cmd.RunConfig(args)
+	case "user":
+		return cmd.RunUser(args)
+	default:
+		return fmt.Errorf("unknown command: %s (available: serve, migrate, etl, config, user)", name)
 	}
 }
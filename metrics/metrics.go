@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace 所有指标的前缀
+const namespace = "gaokao"
+
+// ClickHouse 查询类型，作为 gaokao_clickhouse_query_duration_seconds 的 kind 标签
+const (
+	QueryUniversityList   = "university_list"
+	QueryUniversityCount  = "university_count"
+	QueryMajorGroupBatch  = "major_group_batch"
+	QueryLatestYear       = "latest_year"
+	QueryAdmissionHistory = "admission_history"
+)
+
+// 查询结果，作为 result 标签
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+var (
+	// HTTPRequests 按路由、方法和状态码统计的请求数
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP 请求数",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration 按路由和方法统计的请求耗时
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP 请求耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// ClickHouseQueryDuration 按查询类型统计的 ClickHouse 查询耗时，包含结果扫描
+	ClickHouseQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "clickhouse",
+		Name:      "query_duration_seconds",
+		Help:      "ClickHouse 查询耗时（秒）",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"kind", "result"})
+
+	// ScoreRankCache 一分一段表缓存命中情况，result 为 hit 或 miss
+	ScoreRankCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "score_rank",
+		Name:      "cache_requests_total",
+		Help:      "一分一段表缓存查询次数",
+	}, []string{"result"})
+
+	// ProfilesCreated 创建成功的档案数，包含批量导入
+	ProfilesCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "profile",
+		Name:      "created_total",
+		Help:      "创建的档案数",
+	})
+)
+
+// Result 将错误转换为 result 标签值
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultOK
+}
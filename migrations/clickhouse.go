@@ -0,0 +1,173 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clickHouseFS ClickHouse 迁移文件，命名为 <版本>_<名称>.up.sql / <版本>_<名称>.down.sql
+//
+//go:embed clickhouse/*.sql
+var clickHouseFS embed.FS
+
+var clickHouseFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ClickHouseMigration ClickHouse 迁移，每个文件可包含多条以分号结尾的语句
+type ClickHouseMigration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// loadClickHouseMigrations 读取内嵌的 ClickHouse 迁移文件
+func loadClickHouseMigrations() ([]ClickHouseMigration, error) {
+	files, err := fs.Glob(clickHouseFS, "clickhouse/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*ClickHouseMigration)
+	for _, file := range files {
+		match := clickHouseFilePattern.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("invalid ClickHouse migration file name: %s", file)
+		}
+		version, _ := strconv.Atoi(match[1])
+
+		content, err := clickHouseFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &ClickHouseMigration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("ClickHouse migration version %d is duplicated", version)
+		}
+
+		if match[3] == "up" {
+			migration.Up = splitStatements(string(content))
+		} else {
+			migration.Down = splitStatements(string(content))
+		}
+	}
+
+	migrations := make([]ClickHouseMigration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == nil || migration.Down == nil {
+			return nil, fmt.Errorf("ClickHouse migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	return migrations, nil
+}
+
+// splitStatements 按行尾分号拆分语句，并去掉整行注释
+// 只有注释的文件返回空切片而非 nil，表示迁移在该方向上无需执行语句
+func splitStatements(content string) []string {
+	statements := []string{}
+	var current strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// clickHouseDriver ClickHouse 迁移驱动
+// ClickHouse 不支持事务，状态表使用 ReplacingMergeTree 追加记录，以最新一条记录为准
+type clickHouseDriver struct {
+	db         *sql.DB
+	migrations map[int]ClickHouseMigration
+}
+
+func (d *clickHouseDriver) ensureStatusTable(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+StatusTable+` (
+    version UInt32,
+    name String,
+    applied Bool,
+    updated_at DateTime64(3)
+) ENGINE = ReplacingMergeTree(updated_at)
+ORDER BY version`)
+	return err
+}
+
+func (d *clickHouseDriver) appliedVersions(ctx context.Context) (map[int]time.Time, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT version, argMax(updated_at, updated_at)
+FROM `+StatusTable+`
+GROUP BY version
+HAVING argMax(applied, updated_at)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version uint32
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[int(version)] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// apply 逐条执行语句，全部成功后再写入状态记录
+func (d *clickHouseDriver) apply(ctx context.Context, version int, up bool) error {
+	migration := d.migrations[version]
+	statements := migration.Up
+	if !up {
+		statements = migration.Down
+	}
+
+	for i, statement := range statements {
+		if _, err := d.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		"INSERT INTO "+StatusTable+" (version, name, applied, updated_at) VALUES (?, ?, ?, ?)",
+		uint32(version), migration.Name, up, time.Now())
+	return err
+}
+
+// NewClickHouseMigrator 创建 ClickHouse 迁移器
+func NewClickHouseMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadClickHouseMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &clickHouseDriver{db: db, migrations: make(map[int]ClickHouseMigration, len(migrations))}
+	steps := make([]step, 0, len(migrations))
+	for _, migration := range migrations {
+		d.migrations[migration.Version] = migration
+		steps = append(steps, step{version: migration.Version, name: migration.Name})
+	}
+	return newMigrator("clickhouse", d, steps)
+}
@@ -0,0 +1,2 @@
+-- 该迁移使用 IF NOT EXISTS 接管已由 notebook 建表的部署，表中的数据并非由迁移写入
+-- 回滚时不删除表，需要删除时手动执行 DROP TABLE gaokao2025
@@ -0,0 +1,49 @@
+-- 录取数据表，结构与 scripts/db_migrate.ipynb 生成的建表语句一致
+-- 使用 IF NOT EXISTS 以便接管已由 notebook 建表的部署
+CREATE TABLE IF NOT EXISTS gaokao2025 (
+    `major_id` String,
+    `school_name` String,
+    `school_code` String,
+    `major_group_code` String,
+    `major_name` String,
+    `major_code` String,
+    `major_category` String,
+    `major_description` String,
+    `source_province` Enum8('湖北' = 1),
+    `subject_category` Enum8('物理' = 1, '历史' = 2),
+    `subject_requirement_raw` String,
+    `tuition_fee` String,
+    `is_new_major` Bool,
+    `admission_batch` Enum8('本科批' = 1, '专科批' = 2),
+    `enrollment_plan` UInt16,
+    `enrollment_type` Enum8('' = 1, '国家专项计划' = 2, '地方专项计划' = 3, '专本联合培养' = 4, '单设志愿-高校专项' = 5, '单设志愿-高水平运动队' = 6),
+    `enrollment_plan_year` UInt16,
+    `enrollment_plan_2024` UInt16,
+    `study_duration` UInt8,
+    `require_physics` Bool,
+    `require_chemistry` Bool,
+    `require_biology` Bool,
+    `require_politics` Bool,
+    `require_history` Bool,
+    `require_geography` Bool,
+    `min_score_2024` UInt16,
+    `min_rank_2024` UInt32,
+    `admission_num_2024` UInt16,
+    `major_min_score_2024` UInt16,
+    `major_min_rank_2024` UInt32,
+    `major_avg_score_2024` UInt16,
+    `major_avg_rank_2024` UInt32,
+    `major_max_score_2024` UInt16,
+    `major_max_rank_2024` UInt32,
+    `major_admission_num_2024` UInt16,
+    `school_province` String,
+    `school_city` String,
+    `school_type` String,
+    `school_ownership` Enum8('公办' = 1, '内地与港澳台合作办学' = 2, '中外合作办学' = 3, '民办' = 4, '境外高校独立办学' = 5),
+    `school_authority` String,
+    `school_level` String,
+    `school_tags` String,
+    `education_level` Enum8('本科' = 1, '职业本科' = 2, '专科' = 3),
+    `id` UInt32
+) ENGINE = MergeTree()
+ORDER BY (id);
@@ -0,0 +1,40 @@
+-- 历年录取数据表，每行对应 (省份, 院校, 专业组, 专业, 年份)，major_code 为空表示专业组整体
+-- ReplacingMergeTree 按排序键去重，重复导入同一年份的数据会覆盖旧数据
+CREATE TABLE IF NOT EXISTS admission_history (
+    `source_province` Enum8('湖北' = 1),
+    `subject_category` Enum8('物理' = 1, '历史' = 2),
+    `school_code` String,
+    `major_group_code` String,
+    `major_code` String,
+    `year` UInt16,
+    `min_score` Nullable(UInt16),
+    `min_rank` Nullable(UInt32),
+    `avg_score` Nullable(UInt16),
+    `avg_rank` Nullable(UInt32),
+    `max_score` Nullable(UInt16),
+    `max_rank` Nullable(UInt32),
+    `admission_num` Nullable(UInt16),
+    `plan_num` Nullable(UInt16)
+) ENGINE = ReplacingMergeTree()
+ORDER BY (source_province, school_code, major_group_code, major_code, year);
+
+-- 从 gaokao2025 的 2024 年列回填专业组数据
+INSERT INTO admission_history
+    (source_province, subject_category, school_code, major_group_code, major_code, year, min_score, min_rank, admission_num)
+SELECT source_province, any(subject_category), school_code, major_group_code, '', 2024,
+    nullIf(min(min_score_2024), 0), nullIf(max(min_rank_2024), 0), nullIf(max(admission_num_2024), 0)
+FROM gaokao2025
+GROUP BY source_province, school_code, major_group_code;
+
+-- 从 gaokao2025 的 2024 年列回填专业数据
+INSERT INTO admission_history
+    (source_province, subject_category, school_code, major_group_code, major_code, year,
+    min_score, min_rank, avg_score, avg_rank, max_score, max_rank, admission_num)
+SELECT source_province, any(subject_category), school_code, major_group_code, major_code, 2024,
+    nullIf(min(major_min_score_2024), 0), nullIf(max(major_min_rank_2024), 0),
+    nullIf(min(major_avg_score_2024), 0), nullIf(max(major_avg_rank_2024), 0),
+    nullIf(max(major_max_score_2024), 0), nullIf(min(major_max_rank_2024), 0),
+    nullIf(max(major_admission_num_2024), 0)
+FROM gaokao2025
+WHERE major_code != ''
+GROUP BY source_province, school_code, major_group_code, major_code;
@@ -0,0 +1,203 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"gaokao-data-analysis/database"
+)
+
+// StatusTable 记录已执行迁移的表名，关系型数据库与 ClickHouse 共用
+const StatusTable = "schema_migrations"
+
+// step 迁移的版本与名称，具体执行内容由 driver 持有
+type step struct {
+	version int
+	name    string
+}
+
+// driver 屏蔽不同存储的迁移执行与状态记录方式
+type driver interface {
+	// ensureStatusTable 创建迁移状态表
+	ensureStatusTable(ctx context.Context) error
+	// appliedVersions 返回已执行的迁移版本及执行时间
+	appliedVersions(ctx context.Context) (map[int]time.Time, error)
+	// apply 执行指定版本的迁移并记录状态，up 为 false 时回滚并删除记录
+	apply(ctx context.Context, version int, up bool) error
+}
+
+// MigrationStatus 单个迁移的执行状态
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Migrator 按版本顺序执行某个存储的迁移
+type Migrator struct {
+	name   string
+	driver driver
+	steps  []step
+}
+
+// newMigrator 创建迁移器，校验版本号唯一并按版本排序
+func newMigrator(name string, d driver, steps []step) (*Migrator, error) {
+	sort.Slice(steps, func(i, j int) bool { return steps[i].version < steps[j].version })
+	for i := range steps {
+		if steps[i].version <= 0 {
+			return nil, fmt.Errorf("%s migration %q has invalid version %d", name, steps[i].name, steps[i].version)
+		}
+		if i > 0 && steps[i].version == steps[i-1].version {
+			return nil, fmt.Errorf("%s migration version %d is duplicated", name, steps[i].version)
+		}
+	}
+	return &Migrator{name: name, driver: d, steps: steps}, nil
+}
+
+// Name 返回迁移目标名称
+func (m *Migrator) Name() string {
+	return m.name
+}
+
+// Status 返回所有迁移的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.steps))
+	for _, s := range m.steps {
+		status := MigrationStatus{Version: s.version, Name: s.name}
+		if appliedAt, ok := applied[s.version]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending 返回未执行的迁移数量
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, s := range m.steps {
+		if _, ok := applied[s.version]; !ok {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// Up 按顺序执行未执行的迁移，target 为 0 时执行到最新版本
+func (m *Migrator) Up(ctx context.Context, target int) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, s := range m.steps {
+		if target > 0 && s.version > target {
+			break
+		}
+		if _, ok := applied[s.version]; ok {
+			continue
+		}
+
+		slog.Info("执行迁移", "target", m.name, "version", s.version, "name", s.name)
+		if err := m.driver.apply(ctx, s.version, true); err != nil {
+			return count, fmt.Errorf("%s migration %d_%s failed: %w", m.name, s.version, s.name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down 按倒序回滚最近执行的 steps 个迁移
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.steps) - 1; i >= 0 && count < steps; i-- {
+		s := m.steps[i]
+		if _, ok := applied[s.version]; !ok {
+			continue
+		}
+
+		slog.Info("回滚迁移", "target", m.name, "version", s.version, "name", s.name)
+		if err := m.driver.apply(ctx, s.version, false); err != nil {
+			return count, fmt.Errorf("%s migration %d_%s rollback failed: %w", m.name, s.version, s.name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// applied 确保状态表存在并读取已执行的版本
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	if err := m.driver.ensureStatusTable(ctx); err != nil {
+		return nil, fmt.Errorf("create %s %s table failed: %w", m.name, StatusTable, err)
+	}
+	applied, err := m.driver.appliedVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read %s %s table failed: %w", m.name, StatusTable, err)
+	}
+	return applied, nil
+}
+
+// Migration targets
+const (
+	TargetAll        = "all"
+	TargetRelational = "relational"
+	TargetClickHouse = "clickhouse"
+)
+
+// ForTarget 根据目标名称创建迁移器，all 包含关系型数据库以及作为录取数据后端的 ClickHouse
+// 关系型数据库的录取数据表迁移只在录取数据后端为 sql 时包含
+func ForTarget(target string) ([]*Migrator, error) {
+	var migrators []*Migrator
+
+	if target == TargetAll || target == TargetRelational {
+		db := database.GetDB()
+		if db == nil {
+			return nil, fmt.Errorf("数据库连接未初始化")
+		}
+		migrator, err := NewRelationalMigrator(db, database.GetAdmissionBackend() == database.AdmissionBackendSQL)
+		if err != nil {
+			return nil, err
+		}
+		migrators = append(migrators, migrator)
+	}
+
+	useClickHouse := target == TargetClickHouse ||
+		(target == TargetAll && database.GetAdmissionBackend() == database.AdmissionBackendClickHouse)
+	if useClickHouse {
+		conn := database.GetClickHouse()
+		if conn == nil {
+			return nil, fmt.Errorf("ClickHouse连接未初始化")
+		}
+		migrator, err := NewClickHouseMigrator(conn)
+		if err != nil {
+			return nil, err
+		}
+		migrators = append(migrators, migrator)
+	}
+
+	if migrators == nil {
+		return nil, fmt.Errorf("unknown migration target: %s", target)
+	}
+	return migrators, nil
+}
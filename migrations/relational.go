@@ -0,0 +1,157 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RelationalMigration 关系型数据库迁移，通过 GORM Migrator 编写以兼容 MySQL、PostgreSQL 和 SQLite
+// 建表使用 relational_schema.go 中按版本冻结的结构体，不引用 models 中的模型，避免模型变更改写已发布的迁移
+type RelationalMigration struct {
+	Version int
+	Name    string
+	// Admission 录取数据表的迁移，只在 ADMISSION_BACKEND=sql 时执行，使用 ClickHouse 时不执行也不计入待执行
+	Admission bool
+	Up        func(tx *gorm.DB) error
+	Down      func(tx *gorm.DB) error
+}
+
+// relationalMigrations 关系型数据库迁移列表，新增迁移追加到末尾，已发布的迁移不要修改
+var relationalMigrations = []RelationalMigration{
+	{
+		// 基线迁移：对已由 AutoMigrate 建表的库同样适用
+		Version: 1,
+		Name:    "create_users_and_profiles",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&userV1{},
+				&userProfileV1{},
+				&profileMemberV1{},
+				&profileSnapshotV1{},
+				&savedRecommendationV1{},
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(
+				&savedRecommendationV1{},
+				&profileSnapshotV1{},
+				&profileMemberV1{},
+				&userProfileV1{},
+				&userV1{},
+			)
+		},
+	},
+	{
+		// 录取数据表，ADMISSION_BACKEND=sql 时使用
+		Version:   2,
+		Name:      "create_admission_table",
+		Admission: true,
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&admissionRecordV2{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&admissionRecordV2{})
+		},
+	},
+	{
+		// 历年录取数据表，并从 gaokao2025 的 2024 年列回填
+		Version:   3,
+		Name:      "create_admission_history",
+		Admission: true,
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&admissionHistoryV3{}); err != nil {
+				return err
+			}
+			if err := tx.Exec(`INSERT INTO admission_history
+	(source_province, subject_category, school_code, major_group_code, major_code, year, min_score, min_rank, admission_num)
+SELECT source_province, MIN(subject_category), school_code, major_group_code, '', 2024,
+	NULLIF(MIN(min_score_2024), 0), NULLIF(MAX(min_rank_2024), 0), NULLIF(MAX(admission_num_2024), 0)
+FROM gaokao2025
+GROUP BY source_province, school_code, major_group_code`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`INSERT INTO admission_history
+	(source_province, subject_category, school_code, major_group_code, major_code, year,
+	min_score, min_rank, avg_score, avg_rank, max_score, max_rank, admission_num)
+SELECT source_province, MIN(subject_category), school_code, major_group_code, major_code, 2024,
+	NULLIF(MIN(major_min_score_2024), 0), NULLIF(MAX(major_min_rank_2024), 0),
+	NULLIF(MIN(major_avg_score_2024), 0), NULLIF(MAX(major_avg_rank_2024), 0),
+	NULLIF(MAX(major_max_score_2024), 0), NULLIF(MIN(major_max_rank_2024), 0),
+	NULLIF(MAX(major_admission_num_2024), 0)
+FROM gaokao2025
+WHERE major_code <> ''
+GROUP BY source_province, school_code, major_group_code, major_code`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&admissionHistoryV3{})
+		},
+	},
+}
+
+// schemaMigration 迁移状态表记录
+type schemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return StatusTable
+}
+
+// relationalDriver 关系型数据库迁移驱动
+type relationalDriver struct {
+	db         *gorm.DB
+	migrations map[int]RelationalMigration
+}
+
+func (d *relationalDriver) ensureStatusTable(ctx context.Context) error {
+	return d.db.WithContext(ctx).AutoMigrate(&schemaMigration{})
+}
+
+func (d *relationalDriver) appliedVersions(ctx context.Context) (map[int]time.Time, error) {
+	var records []schemaMigration
+	if err := d.db.WithContext(ctx).Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time, len(records))
+	for _, record := range records {
+		applied[record.Version] = record.AppliedAt
+	}
+	return applied, nil
+}
+
+// apply 在事务中执行迁移并更新状态表（MySQL 的 DDL 会隐式提交）
+func (d *relationalDriver) apply(ctx context.Context, version int, up bool) error {
+	migration := d.migrations[version]
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if !up {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, "version = ?", version).Error
+		}
+
+		if err := migration.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Version: version, Name: migration.Name, AppliedAt: time.Now()}).Error
+	})
+}
+
+// NewRelationalMigrator 创建关系型数据库迁移器，admission 为 false 时不包含录取数据表的迁移
+func NewRelationalMigrator(db *gorm.DB, admission bool) (*Migrator, error) {
+	d := &relationalDriver{db: db, migrations: make(map[int]RelationalMigration, len(relationalMigrations))}
+	steps := make([]step, 0, len(relationalMigrations))
+	for _, migration := range relationalMigrations {
+		if migration.Admission && !admission {
+			continue
+		}
+		d.migrations[migration.Version] = migration
+		steps = append(steps, step{version: migration.Version, name: migration.Name})
+	}
+	return newMigrator("relational", d, steps)
+}
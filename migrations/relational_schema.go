@@ -0,0 +1,156 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 本文件中的结构体是各版本迁移发布时的表结构快照，与 models 中的模型相互独立
+// 模型变更时不要修改这些结构体，而是新增迁移并在其中定义新的快照
+
+// v1: create_users_and_profiles
+
+type userV1 struct {
+	ID           string  `gorm:"type:varchar(36);primaryKey"`
+	Phone        *string `gorm:"type:varchar(20);uniqueIndex"`
+	Email        *string `gorm:"type:varchar(255);uniqueIndex"`
+	Nickname     string  `gorm:"type:varchar(100)"`
+	Role         string  `gorm:"type:varchar(20);not null;default:student"`
+	PasswordHash string  `gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+func (userV1) TableName() string { return "users" }
+
+type userProfileV1 struct {
+	ID         string  `gorm:"type:varchar(36);primaryKey"`
+	OwnerID    *string `gorm:"type:varchar(36);index"`
+	ClaimToken *string `gorm:"type:varchar(64)"`
+	Username   string  `gorm:"type:varchar(100);not null"`
+	Gender     *string `gorm:"type:varchar(20)"`
+	Province   string  `gorm:"type:varchar(50);not null"`
+	Score      int32   `gorm:"not null"`
+	Rank       int32   `gorm:"not null"`
+	Subjects   string  `gorm:"type:json;not null"`
+	Preference string  `gorm:"type:json;not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+func (userProfileV1) TableName() string { return "user_profiles" }
+
+type profileMemberV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	ProfileID string `gorm:"type:varchar(36);not null;uniqueIndex:idx_profile_member"`
+	UserID    string `gorm:"type:varchar(36);not null;uniqueIndex:idx_profile_member;index"`
+	Relation  string `gorm:"type:varchar(20);not null"`
+	CreatedAt time.Time
+}
+
+func (profileMemberV1) TableName() string { return "profile_members" }
+
+type profileSnapshotV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	ProfileID string `gorm:"type:varchar(36);not null;index"`
+	Label     string `gorm:"type:varchar(50);not null"`
+	ExamDate  *time.Time
+	Score     int32 `gorm:"not null"`
+	Rank      int32
+	Subjects  string `gorm:"type:json;not null"`
+	CreatedAt time.Time
+}
+
+func (profileSnapshotV1) TableName() string { return "profile_snapshots" }
+
+type savedRecommendationV1 struct {
+	ID             uint   `gorm:"primaryKey"`
+	ProfileID      string `gorm:"type:varchar(36);not null;index"`
+	SortOrder      int32  `gorm:"not null"`
+	SchoolCode     string `gorm:"type:varchar(20);not null"`
+	UniversityName string `gorm:"type:varchar(100)"`
+	GroupCode      string `gorm:"type:varchar(20);not null"`
+	MajorCodes     string `gorm:"type:json"`
+	Strategy       int32
+	Probability    int32
+	Note           string `gorm:"type:varchar(255)"`
+	CreatedAt      time.Time
+}
+
+func (savedRecommendationV1) TableName() string { return "saved_recommendations" }
+
+// v2: create_admission_table
+
+type admissionRecordV2 struct {
+	ID                    uint32  `gorm:"primaryKey;autoIncrement:false"`
+	MajorID               string  `gorm:"type:varchar(32)"`
+	SchoolCode            string  `gorm:"type:varchar(20);index:idx_school_group"`
+	SchoolName            string  `gorm:"type:varchar(100);index"`
+	MajorGroupCode        string  `gorm:"type:varchar(20);index:idx_school_group"`
+	MajorCode             string  `gorm:"type:varchar(20)"`
+	MajorName             string  `gorm:"type:varchar(255)"`
+	MajorCategory         string  `gorm:"type:varchar(100)"`
+	MajorDescription      *string `gorm:"type:text"`
+	SourceProvince        int8    `gorm:"index"`
+	SubjectCategory       int8    `gorm:"index"`
+	SubjectRequirementRaw string  `gorm:"type:varchar(100)"`
+	RequirePhysics        bool
+	RequireChemistry      bool
+	RequireBiology        bool
+	RequirePolitics       bool
+	RequireHistory        bool
+	RequireGeography      bool
+	TuitionFee            *string `gorm:"type:varchar(50)"`
+	StudyDuration         *int32
+	IsNewMajor            bool
+	AdmissionBatch        int8
+	EnrollmentType        int8
+	EnrollmentPlan        int32
+	EnrollmentPlanYear    int32
+	EnrollmentPlan2024    *int32 `gorm:"column:enrollment_plan_2024"`
+	MinScore2024          int32  `gorm:"column:min_score_2024;index"`
+	MinRank2024           int32  `gorm:"column:min_rank_2024"`
+	AdmissionNum2024      int32  `gorm:"column:admission_num_2024"`
+	MajorMinScore2024     *int32 `gorm:"column:major_min_score_2024"`
+	MajorMinRank2024      *int32 `gorm:"column:major_min_rank_2024"`
+	MajorAvgScore2024     *int32 `gorm:"column:major_avg_score_2024"`
+	MajorAvgRank2024      *int32 `gorm:"column:major_avg_rank_2024"`
+	MajorMaxScore2024     *int32 `gorm:"column:major_max_score_2024"`
+	MajorMaxRank2024      *int32 `gorm:"column:major_max_rank_2024"`
+	MajorAdmissionNum2024 *int32 `gorm:"column:major_admission_num_2024"`
+	SchoolProvince        string `gorm:"type:varchar(50)"`
+	SchoolCity            string `gorm:"type:varchar(50)"`
+	SchoolType            string `gorm:"type:varchar(100)"`
+	SchoolOwnership       int8
+	SchoolAuthority       string `gorm:"type:varchar(100)"`
+	SchoolLevel           string `gorm:"type:varchar(100)"`
+	SchoolTags            string `gorm:"type:varchar(500)"`
+	EducationLevel        int8
+}
+
+func (admissionRecordV2) TableName() string { return "gaokao2025" }
+
+// v3: create_admission_history
+
+type admissionHistoryV3 struct {
+	ID              uint   `gorm:"primaryKey"`
+	SourceProvince  int8   `gorm:"not null;uniqueIndex:idx_admission_history_key"`
+	SubjectCategory int8   `gorm:"not null"`
+	SchoolCode      string `gorm:"type:varchar(20);not null;uniqueIndex:idx_admission_history_key"`
+	GroupCode       string `gorm:"column:major_group_code;type:varchar(20);not null;uniqueIndex:idx_admission_history_key"`
+	MajorCode       string `gorm:"type:varchar(20);not null;uniqueIndex:idx_admission_history_key"`
+	Year            int32  `gorm:"not null;uniqueIndex:idx_admission_history_key"`
+	MinScore        *int32
+	MinRank         *int32
+	AvgScore        *int32
+	AvgRank         *int32
+	MaxScore        *int32
+	MaxRank         *int32
+	AdmissionNum    *int32
+	PlanNum         *int32
+}
+
+func (admissionHistoryV3) TableName() string { return "admission_history" }
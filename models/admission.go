@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"gaokao-data-analysis/database"
+	"gaokao-data-analysis/errcode"
+)
+
+// AdmissionRepository 录取数据（gaokao2025 表）查询接口
+// 默认由 ClickHouse 实现，小规模部署和测试可切换为 GORM（MySQL/PostgreSQL/SQLite）实现
+type AdmissionRepository interface {
+	// CountUniversities 统计符合条件的院校数量
+	CountUniversities(ctx context.Context, filter *UniversityFilter) (int64, error)
+	// ListUniversityGroups 分页查询符合条件的院校专业组，按院校名称排序
+	ListUniversityGroups(ctx context.Context, filter *UniversityFilter, limit, offset int32) ([]UniversityGroupRow, error)
+	// ListMajors 批量查询专业组内的专业，按院校代码、专业组代码、专业名称排序
+	ListMajors(ctx context.Context, groups []SchoolGroupPair, filter *MajorFilter) ([]GaokaoData, error)
+	// LatestAdmissionYear 返回省份已有录取分数的最新年份，没有数据时返回 0
+	LatestAdmissionYear(ctx context.Context, sourceProvince int) (int32, error)
+	// ListAdmissionHistory 批量查询专业组及其专业的历年录取数据，年份从新到旧
+	ListAdmissionHistory(ctx context.Context, groups []SchoolGroupPair, filter *HistoryFilter) ([]AdmissionHistory, error)
+}
+
+// UniversityFilter 院校查询条件，零值表示不限
+type UniversityFilter struct {
+	// 生源省份枚举值
+	SourceProvince int
+	// 专业组最低分范围
+	MinScore int32
+	MaxScore int32
+	// 分数范围对应的录取年份
+	ScoreYear int32
+	// 科目要求
+	Subjects *SubjectFilter
+	// 院校所在城市，满足任一即可
+	Cities []string
+	// 办学性质枚举值，满足任一即可
+	Ownerships []int
+	// 院校标签，满足任一即可
+	Tags []string
+}
+
+// MajorFilter 专业查询条件，零值表示不限
+type MajorFilter struct {
+	// 生源省份枚举值
+	SourceProvince int
+	// 科目要求
+	Subjects *SubjectFilter
+}
+
+// UniversityGroupRow 院校专业组聚合结果
+type UniversityGroupRow struct {
+	RecruitCode    string   `db:"recruit_code"`
+	UniversityName string   `db:"university_name"`
+	Province       string   `db:"province"`
+	Category       string   `db:"category"`
+	Tags           []string `db:"tags"`
+	GroupCode      string   `db:"group_code"`
+	MajorCount     int      `db:"major_count"`
+}
+
+// AdmissionRecord gaokao2025 表结构，用于在关系型数据库中建表（ADMISSION_BACKEND=sql）
+// 枚举列与 ClickHouse 的 Enum8 取值保持一致，存储为整数
+// *_2024 列只保存导入文件中的上一年录取数据，ETL 据此写入 admission_history，查询一律读取 admission_history
+type AdmissionRecord struct {
+	ID                    uint32  `gorm:"primaryKey;autoIncrement:false"`
+	MajorID               string  `gorm:"type:varchar(32)"`
+	SchoolCode            string  `gorm:"type:varchar(20);index:idx_school_group"`
+	SchoolName            string  `gorm:"type:varchar(100);index"`
+	MajorGroupCode        string  `gorm:"type:varchar(20);index:idx_school_group"`
+	MajorCode             string  `gorm:"type:varchar(20)"`
+	MajorName             string  `gorm:"type:varchar(255)"`
+	MajorCategory         string  `gorm:"type:varchar(100)"`
+	MajorDescription      *string `gorm:"type:text"`
+	SourceProvince        int8    `gorm:"index"`
+	SubjectCategory       int8    `gorm:"index"`
+	SubjectRequirementRaw string  `gorm:"type:varchar(100)"`
+	RequirePhysics        bool
+	RequireChemistry      bool
+	RequireBiology        bool
+	RequirePolitics       bool
+	RequireHistory        bool
+	RequireGeography      bool
+	TuitionFee            *string `gorm:"type:varchar(50)"`
+	StudyDuration         *int32
+	IsNewMajor            bool
+	AdmissionBatch        int8
+	EnrollmentType        int8
+	EnrollmentPlan        int32
+	EnrollmentPlanYear    int32
+	EnrollmentPlan2024    *int32 `gorm:"column:enrollment_plan_2024"`
+	MinScore2024          int32  `gorm:"column:min_score_2024;index"`
+	MinRank2024           int32  `gorm:"column:min_rank_2024"`
+	AdmissionNum2024      int32  `gorm:"column:admission_num_2024"`
+	MajorMinScore2024     *int32 `gorm:"column:major_min_score_2024"`
+	MajorMinRank2024      *int32 `gorm:"column:major_min_rank_2024"`
+	MajorAvgScore2024     *int32 `gorm:"column:major_avg_score_2024"`
+	MajorAvgRank2024      *int32 `gorm:"column:major_avg_rank_2024"`
+	MajorMaxScore2024     *int32 `gorm:"column:major_max_score_2024"`
+	MajorMaxRank2024      *int32 `gorm:"column:major_max_rank_2024"`
+	MajorAdmissionNum2024 *int32 `gorm:"column:major_admission_num_2024"`
+	SchoolProvince        string `gorm:"type:varchar(50)"`
+	SchoolCity            string `gorm:"type:varchar(50)"`
+	SchoolType            string `gorm:"type:varchar(100)"`
+	SchoolOwnership       int8
+	SchoolAuthority       string `gorm:"type:varchar(100)"`
+	SchoolLevel           string `gorm:"type:varchar(100)"`
+	SchoolTags            string `gorm:"type:varchar(500)"`
+	EducationLevel        int8
+}
+
+// TableName 与 ClickHouse 表名保持一致
+func (AdmissionRecord) TableName() string {
+	return TABLE
+}
+
+// GetAdmissionRepository 根据 ADMISSION_BACKEND 配置返回录取数据查询实现
+func GetAdmissionRepository() (AdmissionRepository, error) {
+	switch database.GetAdmissionBackend() {
+	case database.AdmissionBackendSQL:
+		db := database.GetDB()
+		if db == nil {
+			return nil, errcode.Wrap(errcode.AdmissionUnavailable, errors.New("数据库连接未初始化"))
+		}
+		return NewGormAdmissionRepository(db), nil
+	default:
+		db := database.GetClickHouse()
+		if db == nil {
+			return nil, errcode.Wrap(errcode.AdmissionUnavailable, errors.New("ClickHouse连接未初始化"))
+		}
+		if !database.IsClickHouseReady() {
+			return nil, errcode.Wrap(errcode.AdmissionUnavailable, errors.New("ClickHouse尚未连接，正在后台重连"))
+		}
+		return NewClickHouseAdmissionRepository(db), nil
+	}
+}
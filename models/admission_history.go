@@ -0,0 +1,140 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// AdmissionHistoryTable 历年录取数据表
+const AdmissionHistoryTable = "admission_history"
+
+// 历年录取数据年数的默认值与上限
+const (
+	DefaultHistoryYears = 3
+	MaxHistoryYears     = 5
+)
+
+// AdmissionHistory 历年录取数据，每行对应 (省份, 院校, 专业组, 专业, 年份)
+// MajorCode 为空时表示专业组整体的录取数据；取值为空表示该年没有对应数据
+type AdmissionHistory struct {
+	ID              uint   `gorm:"primaryKey" json:"-" db:"id"`
+	SourceProvince  int8   `gorm:"not null;uniqueIndex:idx_admission_history_key" json:"-" db:"source_province"`
+	SubjectCategory int8   `gorm:"not null" json:"-" db:"subject_category"`
+	SchoolCode      string `gorm:"type:varchar(20);not null;uniqueIndex:idx_admission_history_key" json:"-" db:"school_code"`
+	GroupCode       string `gorm:"column:major_group_code;type:varchar(20);not null;uniqueIndex:idx_admission_history_key" json:"-" db:"major_group_code"`
+	MajorCode       string `gorm:"type:varchar(20);not null;uniqueIndex:idx_admission_history_key" json:"-" db:"major_code"`
+	AdmissionYearStat
+}
+
+// TableName 与 ClickHouse 表名保持一致
+func (AdmissionHistory) TableName() string {
+	return AdmissionHistoryTable
+}
+
+// AdmissionYearStat 某一年的录取数据
+type AdmissionYearStat struct {
+	// 年份
+	Year int32 `gorm:"not null;uniqueIndex:idx_admission_history_key" json:"year" db:"year"`
+	// 最低分及位次
+	MinScore *int32 `json:"min_score" db:"min_score"`
+	MinRank  *int32 `json:"min_rank" db:"min_rank"`
+	// 平均分及位次
+	AvgScore *int32 `json:"avg_score" db:"avg_score"`
+	AvgRank  *int32 `json:"avg_rank" db:"avg_rank"`
+	// 最高分及位次
+	MaxScore *int32 `json:"max_score" db:"max_score"`
+	MaxRank  *int32 `json:"max_rank" db:"max_rank"`
+	// 录取人数
+	AdmissionNum *int32 `json:"admission_num" db:"admission_num"`
+	// 计划人数
+	PlanNum *int32 `json:"plan_num" db:"plan_num"`
+}
+
+// HistoryFilter 历年录取数据查询条件
+type HistoryFilter struct {
+	// 生源省份枚举值，0 表示不限
+	SourceProvince int
+	// 只返回不早于该年份的数据，0 表示不限
+	FromYear int32
+}
+
+// historyKey 专业组或专业（MajorCode 为空时为专业组）的历年数据索引
+type historyKey struct {
+	SchoolCode string
+	GroupCode  string
+	MajorCode  string
+}
+
+// admissionHistoryIndex 按专业组、专业索引的历年录取数据，年份从新到旧
+type admissionHistoryIndex map[historyKey][]AdmissionYearStat
+
+// group 返回专业组整体的历年数据
+func (idx admissionHistoryIndex) group(schoolCode, groupCode string) []AdmissionYearStat {
+	return idx[historyKey{SchoolCode: schoolCode, GroupCode: groupCode}]
+}
+
+// major 返回专业的历年数据
+func (idx admissionHistoryIndex) major(schoolCode, groupCode, majorCode string) []AdmissionYearStat {
+	return idx[historyKey{SchoolCode: schoolCode, GroupCode: groupCode, MajorCode: majorCode}]
+}
+
+// normalizeHistoryYears 将请求的年数限制在 1~MaxHistoryYears，0 使用默认值
+func normalizeHistoryYears(years int32) int32 {
+	switch {
+	case years <= 0:
+		return DefaultHistoryYears
+	case years > MaxHistoryYears:
+		return MaxHistoryYears
+	default:
+		return years
+	}
+}
+
+// loadAdmissionHistory 查询专业组及其专业最近 years 年的录取数据
+// 年份以该省份已有录取分数的最新年份为基准向前推算，之后招生计划年份的计划人数一并返回
+func loadAdmissionHistory(ctx context.Context, repo AdmissionRepository, groups []SchoolGroupPair, sourceProvince int, years int32) (admissionHistoryIndex, error) {
+	index := make(admissionHistoryIndex)
+	if len(groups) == 0 {
+		return index, nil
+	}
+
+	latestYear, err := repo.LatestAdmissionYear(ctx, sourceProvince)
+	if err != nil {
+		return nil, fmt.Errorf("查询最新录取年份失败: %w", err)
+	}
+	if latestYear == 0 {
+		return index, nil
+	}
+
+	filter := &HistoryFilter{
+		SourceProvince: sourceProvince,
+		FromYear:       latestYear - normalizeHistoryYears(years) + 1,
+	}
+	rows, err := repo.ListAdmissionHistory(ctx, groups, filter)
+	if err != nil {
+		return nil, fmt.Errorf("查询历年录取数据失败: %w", err)
+	}
+
+	for _, row := range rows {
+		key := historyKey{SchoolCode: row.SchoolCode, GroupCode: row.GroupCode, MajorCode: row.MajorCode}
+		index[key] = append(index[key], row.AdmissionYearStat)
+	}
+
+	slog.DebugContext(ctx, "查询历年录取数据完成",
+		"schoolGroupCount", len(groups),
+		"fromYear", filter.FromYear,
+		"rowCount", len(rows),
+	)
+	return index, nil
+}
+
+// latestStat 返回有最低分数据的最近一年，没有时返回 nil
+func latestStat(history []AdmissionYearStat) *AdmissionYearStat {
+	for i := range history {
+		if history[i].MinScore != nil {
+			return &history[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,445 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gaokao-data-analysis/logs"
+	"gaokao-data-analysis/metrics"
+	"gaokao-data-analysis/telemetry"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// admissionDialect 屏蔽 ClickHouse 与关系型数据库在执行方式和函数上的差异
+type admissionDialect interface {
+	// queryRows 执行原生查询，占位符统一使用 ?
+	queryRows(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	// containsCondition 构建“列包含子串”的条件
+	containsCondition(column, value string) (string, interface{})
+	// historyTable 返回查询历年录取数据时的表表达式
+	historyTable() string
+}
+
+// ClickHouseAdmissionRepository ClickHouse 实现
+type ClickHouseAdmissionRepository struct {
+	db *sql.DB
+}
+
+// NewClickHouseAdmissionRepository 创建 ClickHouse 录取数据查询实现
+func NewClickHouseAdmissionRepository(db *sql.DB) *ClickHouseAdmissionRepository {
+	return &ClickHouseAdmissionRepository{db: db}
+}
+
+func (r *ClickHouseAdmissionRepository) queryRows(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	// 将 trace ID 写入 system.query_log 的 log_comment，便于按请求查找 ClickHouse 查询
+	if traceID := logs.TraceID(ctx); traceID != "" {
+		ctx = clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"log_comment": traceID}))
+	}
+	// 查询语句记录到 startClickHouseQuery 创建的 span 上
+	trace.SpanFromContext(ctx).SetAttributes(semconv.DBQueryText(query))
+	return r.db.QueryContext(ctx, query, args...)
+}
+
+// startClickHouseQuery 为一次录取数据查询创建 span 并开始计时，覆盖查询执行和结果扫描
+// 返回的函数结束 span 并按 kind 记录耗时，用于 defer finish(&err)
+func startClickHouseQuery(ctx context.Context, operation, kind string, attrs ...attribute.KeyValue) (context.Context, func(*error)) {
+	start := time.Now()
+	attrs = append(attrs, semconv.DBSystemNameClickHouse, semconv.DBOperationName(operation))
+	ctx, span := telemetry.Start(ctx, "clickhouse."+operation, attrs...)
+	return ctx, func(errp *error) {
+		metrics.ClickHouseQueryDuration.WithLabelValues(kind, metrics.Result(*errp)).Observe(time.Since(start).Seconds())
+		telemetry.End(span, errp)
+	}
+}
+
+func (r *ClickHouseAdmissionRepository) containsCondition(column, value string) (string, interface{}) {
+	return fmt.Sprintf("positionUTF8(%s, ?) > 0", column), value
+}
+
+// historyTable FINAL 保证读取 ReplacingMergeTree 去重后的结果
+func (r *ClickHouseAdmissionRepository) historyTable() string {
+	return AdmissionHistoryTable + " FINAL"
+}
+
+// CountUniversities 统计符合条件的院校数量
+func (r *ClickHouseAdmissionRepository) CountUniversities(ctx context.Context, filter *UniversityFilter) (total int64, err error) {
+	ctx, finish := startClickHouseQuery(ctx, "CountUniversities", metrics.QueryUniversityCount)
+	defer finish(&err)
+	return countUniversities(ctx, r, filter)
+}
+
+// ListUniversityGroups 分页查询符合条件的院校专业组
+func (r *ClickHouseAdmissionRepository) ListUniversityGroups(ctx context.Context, filter *UniversityFilter, limit, offset int32) (rows []UniversityGroupRow, err error) {
+	ctx, finish := startClickHouseQuery(ctx, "ListUniversityGroups", metrics.QueryUniversityList)
+	defer finish(&err)
+	return listUniversityGroups(ctx, r, filter, limit, offset)
+}
+
+// ListMajors 批量查询专业组内的专业
+func (r *ClickHouseAdmissionRepository) ListMajors(ctx context.Context, groups []SchoolGroupPair, filter *MajorFilter) (rows []GaokaoData, err error) {
+	ctx, finish := startClickHouseQuery(ctx, "ListMajors", metrics.QueryMajorGroupBatch, attribute.Int("school_group_count", len(groups)))
+	defer finish(&err)
+	return listMajors(ctx, r, groups, filter)
+}
+
+// LatestAdmissionYear 返回省份已有录取分数的最新年份
+func (r *ClickHouseAdmissionRepository) LatestAdmissionYear(ctx context.Context, sourceProvince int) (year int32, err error) {
+	ctx, finish := startClickHouseQuery(ctx, "LatestAdmissionYear", metrics.QueryLatestYear)
+	defer finish(&err)
+	return latestAdmissionYear(ctx, r, sourceProvince)
+}
+
+// ListAdmissionHistory 批量查询历年录取数据
+func (r *ClickHouseAdmissionRepository) ListAdmissionHistory(ctx context.Context, groups []SchoolGroupPair, filter *HistoryFilter) (rows []AdmissionHistory, err error) {
+	ctx, finish := startClickHouseQuery(ctx, "ListAdmissionHistory", metrics.QueryAdmissionHistory, attribute.Int("school_group_count", len(groups)))
+	defer finish(&err)
+	return listAdmissionHistory(ctx, r, groups, filter)
+}
+
+// GormAdmissionRepository GORM 实现，支持 MySQL、PostgreSQL 和 SQLite
+type GormAdmissionRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAdmissionRepository 创建 GORM 录取数据查询实现
+func NewGormAdmissionRepository(db *gorm.DB) *GormAdmissionRepository {
+	return &GormAdmissionRepository{db: db}
+}
+
+func (r *GormAdmissionRepository) queryRows(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.db.WithContext(ctx).Raw(query, args...).Rows()
+}
+
+func (r *GormAdmissionRepository) containsCondition(column, value string) (string, interface{}) {
+	return column + " LIKE ?", "%" + value + "%"
+}
+
+func (r *GormAdmissionRepository) historyTable() string {
+	return AdmissionHistoryTable
+}
+
+// CountUniversities 统计符合条件的院校数量
+func (r *GormAdmissionRepository) CountUniversities(ctx context.Context, filter *UniversityFilter) (int64, error) {
+	return countUniversities(ctx, r, filter)
+}
+
+// ListUniversityGroups 分页查询符合条件的院校专业组
+func (r *GormAdmissionRepository) ListUniversityGroups(ctx context.Context, filter *UniversityFilter, limit, offset int32) ([]UniversityGroupRow, error) {
+	return listUniversityGroups(ctx, r, filter, limit, offset)
+}
+
+// ListMajors 批量查询专业组内的专业
+func (r *GormAdmissionRepository) ListMajors(ctx context.Context, groups []SchoolGroupPair, filter *MajorFilter) ([]GaokaoData, error) {
+	return listMajors(ctx, r, groups, filter)
+}
+
+// LatestAdmissionYear 返回省份已有录取分数的最新年份
+func (r *GormAdmissionRepository) LatestAdmissionYear(ctx context.Context, sourceProvince int) (int32, error) {
+	return latestAdmissionYear(ctx, r, sourceProvince)
+}
+
+// ListAdmissionHistory 批量查询历年录取数据
+func (r *GormAdmissionRepository) ListAdmissionHistory(ctx context.Context, groups []SchoolGroupPair, filter *HistoryFilter) ([]AdmissionHistory, error) {
+	return listAdmissionHistory(ctx, r, groups, filter)
+}
+
+// ==================== Shared Queries ====================
+
+// buildUniversityConditions 将院校查询条件添加到查询构建器
+func buildUniversityConditions(qb *QueryBuilder, d admissionDialect, filter *UniversityFilter) {
+	// 处理省份条件
+	if filter.SourceProvince > 0 {
+		qb.AddCondition("source_province = ?", filter.SourceProvince)
+	}
+
+	// 处理分数范围条件：按专业组在 ScoreYear 的最低分筛选
+	if filter.MinScore > 0 || filter.MaxScore > 0 {
+		scoreQuery := fmt.Sprintf(`(school_code, major_group_code) IN (
+	SELECT school_code, major_group_code
+	FROM %s
+	WHERE major_code = '' AND year = ? AND min_score >= ? AND min_score <= ?`, d.historyTable())
+		scoreArgs := []interface{}{filter.ScoreYear, filter.MinScore, filter.MaxScore}
+		if filter.SourceProvince > 0 {
+			scoreQuery += " AND source_province = ?"
+			scoreArgs = append(scoreArgs, filter.SourceProvince)
+		}
+		qb.AddCondition(scoreQuery+")", scoreArgs...)
+	}
+
+	// 处理科目条件
+	if filter.Subjects != nil {
+		qb.AddConditions(filter.Subjects.BuildSubjectConditions())
+	}
+
+	// 处理城市筛选
+	if len(filter.Cities) > 0 {
+		citiesCondition := make([]string, len(filter.Cities))
+		cityArgs := make([]interface{}, len(filter.Cities))
+		for i, city := range filter.Cities {
+			citiesCondition[i] = "school_city = ?"
+			cityArgs[i] = city
+		}
+		qb.AddCondition("("+strings.Join(citiesCondition, " OR ")+")", cityArgs...)
+	}
+
+	// 处理院校类型筛选：办学性质之间取并集，院校标签之间取并集，两类之间取交集
+	var typeConditions []string
+	var typeArgs []interface{}
+	if len(filter.Ownerships) > 0 {
+		ownershipConditions := make([]string, len(filter.Ownerships))
+		for i, ownership := range filter.Ownerships {
+			ownershipConditions[i] = "school_ownership = ?"
+			typeArgs = append(typeArgs, ownership)
+		}
+		typeConditions = append(typeConditions, "("+strings.Join(ownershipConditions, " OR ")+")")
+	}
+	if len(filter.Tags) > 0 {
+		tagsConditions := make([]string, len(filter.Tags))
+		for i, tag := range filter.Tags {
+			condition, arg := d.containsCondition("school_tags", tag)
+			tagsConditions[i] = condition
+			typeArgs = append(typeArgs, arg)
+		}
+		typeConditions = append(typeConditions, "("+strings.Join(tagsConditions, " OR ")+")")
+	}
+	if len(typeConditions) > 0 {
+		qb.AddCondition("("+strings.Join(typeConditions, " AND ")+")", typeArgs...)
+	}
+}
+
+// countUniversities 查询符合条件的院校总数
+func countUniversities(ctx context.Context, d admissionDialect, filter *UniversityFilter) (int64, error) {
+	countQuery := fmt.Sprintf(`SELECT count(DISTINCT school_name)
+FROM %s
+WHERE 1=1
+`, TABLE)
+
+	qb := NewQueryBuilder(countQuery)
+	buildUniversityConditions(qb, d, filter)
+	finalCountQuery, countArgs := qb.Build()
+
+	slog.InfoContext(ctx, "查询符合条件的院校总数", "query", finalCountQuery, "args", countArgs)
+
+	rows, err := d.queryRows(ctx, finalCountQuery, countArgs...)
+	if err != nil {
+		slog.ErrorContext(ctx, "查询志愿总数失败", "error", err.Error())
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total int64
+	if rows.Next() {
+		if err := rows.Scan(&total); err != nil {
+			slog.ErrorContext(ctx, "查询志愿总数失败", "error", err.Error())
+			return 0, err
+		}
+	}
+	return total, rows.Err()
+}
+
+// listUniversityGroups 分页查询院校专业组
+func listUniversityGroups(ctx context.Context, d admissionDialect, filter *UniversityFilter, limit, offset int32) ([]UniversityGroupRow, error) {
+	baseQuery := fmt.Sprintf(`SELECT
+	school_code as recruit_code,
+	school_name as university_name,
+	school_province as province,
+	school_type as category,
+	school_tags as tags,
+	major_group_code as group_code,
+	count(*) as major_count
+FROM %s
+WHERE 1=1
+`, TABLE)
+
+	qb := NewQueryBuilder(baseQuery)
+	buildUniversityConditions(qb, d, filter)
+
+	// 添加分组、排序和分页
+	query, args := qb.Build()
+	query += `
+		GROUP BY
+			recruit_code,
+			university_name,
+			province,
+			category,
+			tags,
+			group_code
+		ORDER BY
+			university_name ASC
+	`
+	query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+
+	slog.InfoContext(ctx, "查询志愿院校分页", "query", query, "args", args)
+
+	rows, err := d.queryRows(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "查询志愿院校分页失败", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result, err := scanRows[UniversityGroupRow](rows)
+	if err != nil {
+		slog.ErrorContext(ctx, "扫描志愿院校结果失败", "error", err.Error())
+		return nil, err
+	}
+	return result, nil
+}
+
+// listMajors 批量查询专业组内的专业
+func listMajors(ctx context.Context, d admissionDialect, groups []SchoolGroupPair, filter *MajorFilter) ([]GaokaoData, error) {
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	baseQuery := fmt.Sprintf(`SELECT
+	school_code,
+	major_group_code,
+	id,
+	major_code,
+	major_name,
+	enrollment_plan_year,
+	enrollment_plan,
+	tuition_fee,
+	study_duration,
+	major_description
+FROM %s
+WHERE (school_code, major_group_code) IN (`, TABLE)
+
+	// 构建 IN 条件的参数占位符
+	inConditions := make([]string, 0, len(groups))
+	inArgs := make([]interface{}, 0, len(groups)*2)
+	for _, sg := range groups {
+		inConditions = append(inConditions, "(?, ?)")
+		inArgs = append(inArgs, sg.SchoolCode, sg.GroupCode)
+	}
+	baseQuery += strings.Join(inConditions, ", ") + ")"
+
+	qb := NewQueryBuilder(baseQuery)
+	qb.args = append(qb.args, inArgs...)
+
+	// 处理省份条件
+	if filter.SourceProvince > 0 {
+		qb.AddCondition("source_province = ?", filter.SourceProvince)
+	}
+
+	// 处理科目条件
+	if filter.Subjects != nil {
+		qb.AddConditions(filter.Subjects.BuildSubjectConditions())
+	}
+
+	query, args := qb.Build()
+	query += " ORDER BY school_code, major_group_code, major_name ASC"
+
+	slog.InfoContext(ctx, "批量查询专业组信息",
+		"schoolGroupCount", len(groups),
+		"query", query,
+		"args", args,
+	)
+
+	rows, err := d.queryRows(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "批量查询专业信息失败", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result, err := scanRows[GaokaoData](rows)
+	if err != nil {
+		slog.ErrorContext(ctx, "扫描专业信息失败", "error", err.Error())
+		return nil, err
+	}
+	return result, nil
+}
+
+// latestAdmissionYear 查询省份已有录取分数的最新年份，只有计划人数的招生计划年份不计入
+func latestAdmissionYear(ctx context.Context, d admissionDialect, sourceProvince int) (int32, error) {
+	qb := NewQueryBuilder(fmt.Sprintf("SELECT max(year) FROM %s WHERE min_score IS NOT NULL", AdmissionHistoryTable))
+	if sourceProvince > 0 {
+		qb.AddCondition("source_province = ?", sourceProvince)
+	}
+	query, args := qb.Build()
+
+	rows, err := d.queryRows(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "查询最新录取年份失败", "error", err.Error())
+		return 0, err
+	}
+	defer rows.Close()
+
+	var year sql.NullInt32
+	if rows.Next() {
+		if err := rows.Scan(&year); err != nil {
+			return 0, err
+		}
+	}
+	return year.Int32, rows.Err()
+}
+
+// listAdmissionHistory 批量查询专业组及其专业的历年录取数据
+func listAdmissionHistory(ctx context.Context, d admissionDialect, groups []SchoolGroupPair, filter *HistoryFilter) ([]AdmissionHistory, error) {
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	baseQuery := fmt.Sprintf(`SELECT
+	school_code,
+	major_group_code,
+	major_code,
+	year,
+	min_score,
+	min_rank,
+	avg_score,
+	avg_rank,
+	max_score,
+	max_rank,
+	admission_num,
+	plan_num
+FROM %s
+WHERE (school_code, major_group_code) IN (`, d.historyTable())
+
+	inConditions := make([]string, 0, len(groups))
+	inArgs := make([]interface{}, 0, len(groups)*2)
+	for _, sg := range groups {
+		inConditions = append(inConditions, "(?, ?)")
+		inArgs = append(inArgs, sg.SchoolCode, sg.GroupCode)
+	}
+	baseQuery += strings.Join(inConditions, ", ") + ")"
+
+	qb := NewQueryBuilder(baseQuery)
+	qb.args = append(qb.args, inArgs...)
+	if filter.SourceProvince > 0 {
+		qb.AddCondition("source_province = ?", filter.SourceProvince)
+	}
+	if filter.FromYear > 0 {
+		qb.AddCondition("year >= ?", filter.FromYear)
+	}
+
+	query, args := qb.Build()
+	query += " ORDER BY school_code, major_group_code, major_code, year DESC"
+
+	slog.InfoContext(ctx, "批量查询历年录取数据", "schoolGroupCount", len(groups), "query", query, "args", args)
+
+	rows, err := d.queryRows(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "批量查询历年录取数据失败", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result, err := scanRows[AdmissionHistory](rows)
+	if err != nil {
+		slog.ErrorContext(ctx, "扫描历年录取数据失败", "error", err.Error())
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,177 @@
+package models
+
+import "math"
+
+// 录取难度趋势
+const (
+	TrendUp     = "up"     // 难度上升，最低位次前移
+	TrendDown   = "down"   // 难度下降，最低位次后移
+	TrendStable = "stable" // 基本持平
+)
+
+// 大小年
+const (
+	BigYear   = "大年"
+	SmallYear = "小年"
+)
+
+// trendStableThreshold 最低位次相对变化不超过该比例时视为持平
+const trendStableThreshold = 0.05
+
+// AdmissionTrend 根据历年录取数据计算的趋势指标，数据不足时对应字段为空
+type AdmissionTrend struct {
+	// 录取难度趋势，比较最近一年与最早一年的最低位次 [up、down、stable]
+	Direction string `json:"direction,omitempty"`
+	// 最低位次波动率（变异系数，百分比）
+	Volatility *float64 `json:"volatility"`
+	// 是否存在大小年，即最低位次连续三年以上交替升降
+	BigSmallYear bool `json:"big_small_year"`
+	// 最近一年为大年还是小年，存在大小年时有值
+	LatestYearType string `json:"latest_year_type,omitempty"`
+	// 今年招生计划人数
+	PlanNum *int32 `json:"plan_num"`
+	// 上一年招生计划人数
+	LastPlanNum *int32 `json:"last_plan_num"`
+	// 计划人数变化量及变化比例（百分比）
+	PlanChange     *int32   `json:"plan_change"`
+	PlanChangeRate *float64 `json:"plan_change_rate"`
+}
+
+// computeAdmissionTrend 根据历年录取数据（年份从新到旧）和 planYear 年的计划人数计算趋势指标
+// 上一年计划人数取历年数据中 planYear-1 年的计划人数
+func computeAdmissionTrend(history []AdmissionYearStat, planYear int32, planNum *int32) AdmissionTrend {
+	var trend AdmissionTrend
+
+	// 位次比分数更能跨年比较，只使用有最低位次的年份
+	var ranks []float64
+	var years []int32
+	for _, stat := range history {
+		if stat.MinRank != nil && *stat.MinRank > 0 {
+			ranks = append(ranks, float64(*stat.MinRank))
+			years = append(years, stat.Year)
+		}
+	}
+
+	if len(ranks) >= 2 {
+		trend.Direction = rankDirection(ranks[len(ranks)-1], ranks[0])
+		trend.Volatility = roundPtr(coefficientOfVariation(ranks) * 100)
+	}
+
+	// 大小年：相邻年份的变化方向交替且变化幅度明显
+	// 只比较相差一年的数据，中间缺少某一年时无法判断是否交替，不标记大小年
+	if len(ranks) >= 3 && consecutiveYears(years) {
+		alternating := true
+		for i := 0; i+2 < len(ranks); i++ {
+			newer := rankDirection(ranks[i+1], ranks[i])
+			older := rankDirection(ranks[i+2], ranks[i+1])
+			if newer == TrendStable || older == TrendStable || newer == older {
+				alternating = false
+				break
+			}
+		}
+		if alternating {
+			trend.BigSmallYear = true
+			trend.LatestYearType = SmallYear
+			if rankDirection(ranks[1], ranks[0]) == TrendUp {
+				trend.LatestYearType = BigYear
+			}
+		}
+	}
+
+	var lastPlanNum *int32
+	for _, stat := range history {
+		if stat.Year == planYear-1 {
+			lastPlanNum = stat.PlanNum
+			break
+		}
+	}
+	trend.setPlanChange(planNum, lastPlanNum)
+	return trend
+}
+
+// computeGroupTrend 计算专业组的趋势指标，计划人数按组内专业汇总
+func computeGroupTrend(history []AdmissionYearStat, majors []VoluntaryMajor) AdmissionTrend {
+	trend := computeAdmissionTrend(history, 0, nil)
+
+	var planNum, lastPlanNum *int32
+	for _, major := range majors {
+		planNum = addInt32Ptr(planNum, major.Trend.PlanNum)
+		lastPlanNum = addInt32Ptr(lastPlanNum, major.Trend.LastPlanNum)
+	}
+	trend.setPlanChange(planNum, lastPlanNum)
+	return trend
+}
+
+// setPlanChange 设置今年与上一年的计划人数及其变化
+func (t *AdmissionTrend) setPlanChange(planNum, lastPlanNum *int32) {
+	t.PlanNum = planNum
+	t.LastPlanNum = lastPlanNum
+	if planNum == nil || lastPlanNum == nil {
+		return
+	}
+
+	change := *planNum - *lastPlanNum
+	t.PlanChange = &change
+	if *lastPlanNum > 0 {
+		t.PlanChangeRate = roundPtr(float64(change) / float64(*lastPlanNum) * 100)
+	}
+}
+
+// consecutiveYears 判断年份（从新到旧）是否逐年连续
+func consecutiveYears(years []int32) bool {
+	for i := 0; i+1 < len(years); i++ {
+		if years[i]-years[i+1] != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// rankDirection 比较前后两年的最低位次，位次数值变小表示难度上升
+func rankDirection(before, after float64) string {
+	change := (after - before) / before
+	switch {
+	case change <= -trendStableThreshold:
+		return TrendUp
+	case change >= trendStableThreshold:
+		return TrendDown
+	default:
+		return TrendStable
+	}
+}
+
+// coefficientOfVariation 计算变异系数（标准差/平均值）
+func coefficientOfVariation(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if mean == 0 {
+		return 0
+	}
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance/float64(len(values))) / mean
+}
+
+// roundPtr 保留两位小数
+func roundPtr(value float64) *float64 {
+	rounded := math.Round(value*100) / 100
+	return &rounded
+}
+
+// addInt32Ptr 累加可能为空的整数，两者都为空时返回空
+func addInt32Ptr(sum, value *int32) *int32 {
+	if value == nil {
+		return sum
+	}
+	total := *value
+	if sum != nil {
+		total += *sum
+	}
+	return &total
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func float64Ptr(v float64) *float64 { return &v }
+
+// yearStat 构造只有最低位次和计划人数的年度数据
+func yearStat(year int32, minRank, planNum *int32) AdmissionYearStat {
+	return AdmissionYearStat{Year: year, MinRank: minRank, PlanNum: planNum}
+}
+
+func TestComputeAdmissionTrend(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  []AdmissionYearStat
+		planYear int32
+		planNum  *int32
+		want     AdmissionTrend
+	}{
+		{
+			name: "没有历年数据",
+			want: AdmissionTrend{},
+		},
+		{
+			name:    "只有一年位次时不计算趋势",
+			history: []AdmissionYearStat{yearStat(2024, int32Ptr(1000), nil)},
+			want:    AdmissionTrend{},
+		},
+		{
+			name: "位次前移为难度上升",
+			history: []AdmissionYearStat{
+				yearStat(2024, int32Ptr(1000), nil),
+				yearStat(2023, int32Ptr(1200), nil),
+			},
+			want: AdmissionTrend{Direction: TrendUp, Volatility: float64Ptr(9.09)},
+		},
+		{
+			name: "位次后移为难度下降",
+			history: []AdmissionYearStat{
+				yearStat(2024, int32Ptr(1200), nil),
+				yearStat(2023, int32Ptr(1000), nil),
+			},
+			want: AdmissionTrend{Direction: TrendDown, Volatility: float64Ptr(9.09)},
+		},
+		{
+			name: "变化不足阈值视为持平",
+			history: []AdmissionYearStat{
+				yearStat(2024, int32Ptr(1020), nil),
+				yearStat(2023, int32Ptr(1000), nil),
+			},
+			want: AdmissionTrend{Direction: TrendStable, Volatility: float64Ptr(0.99)},
+		},
+		{
+			name: "忽略没有位次的年份",
+			history: []AdmissionYearStat{
+				yearStat(2024, nil, nil),
+				yearStat(2023, int32Ptr(0), nil),
+				yearStat(2022, int32Ptr(1000), nil),
+			},
+			want: AdmissionTrend{},
+		},
+		{
+			name: "位次交替升降且最近一年前移为大年",
+			history: []AdmissionYearStat{
+				yearStat(2024, int32Ptr(1000), nil),
+				yearStat(2023, int32Ptr(1200), nil),
+				yearStat(2022, int32Ptr(1000), nil),
+			},
+			want: AdmissionTrend{
+				Direction:      TrendStable,
+				Volatility:     float64Ptr(8.84),
+				BigSmallYear:   true,
+				LatestYearType: BigYear,
+			},
+		},
+		{
+			name: "位次交替升降且最近一年后移为小年",
+			history: []AdmissionYearStat{
+				yearStat(2024, int32Ptr(1200), nil),
+				yearStat(2023, int32Ptr(1000), nil),
+				yearStat(2022, int32Ptr(1200), nil),
+			},
+			want: AdmissionTrend{
+				Direction:      TrendStable,
+				Volatility:     float64Ptr(8.32),
+				BigSmallYear:   true,
+				LatestYearType: SmallYear,
+			},
+		},
+		{
+			name: "同向变化不是大小年",
+			history: []AdmissionYearStat{
+				yearStat(2024, int32Ptr(1000), nil),
+				yearStat(2023, int32Ptr(1200), nil),
+				yearStat(2022, int32Ptr(1400), nil),
+			},
+			want: AdmissionTrend{Direction: TrendUp, Volatility: float64Ptr(13.61)},
+		},
+		{
+			name: "年份不连续时不判断大小年",
+			history: []AdmissionYearStat{
+				yearStat(2024, int32Ptr(1000), nil),
+				yearStat(2022, int32Ptr(1200), nil),
+				yearStat(2021, int32Ptr(1000), nil),
+			},
+			want: AdmissionTrend{Direction: TrendStable, Volatility: float64Ptr(8.84)},
+		},
+		{
+			name: "与上一年计划人数比较",
+			history: []AdmissionYearStat{
+				yearStat(2025, nil, int32Ptr(110)),
+				yearStat(2024, nil, int32Ptr(100)),
+			},
+			planYear: 2025,
+			planNum:  int32Ptr(110),
+			want: AdmissionTrend{
+				PlanNum:        int32Ptr(110),
+				LastPlanNum:    int32Ptr(100),
+				PlanChange:     int32Ptr(10),
+				PlanChangeRate: float64Ptr(10),
+			},
+		},
+		{
+			name:     "上一年计划人数为0时不计算变化比例",
+			history:  []AdmissionYearStat{yearStat(2024, nil, int32Ptr(0))},
+			planYear: 2025,
+			planNum:  int32Ptr(30),
+			want: AdmissionTrend{
+				PlanNum:     int32Ptr(30),
+				LastPlanNum: int32Ptr(0),
+				PlanChange:  int32Ptr(30),
+			},
+		},
+		{
+			name:     "缺少上一年数据时不计算变化",
+			history:  []AdmissionYearStat{yearStat(2023, nil, int32Ptr(100))},
+			planYear: 2025,
+			planNum:  int32Ptr(110),
+			want:     AdmissionTrend{PlanNum: int32Ptr(110)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeAdmissionTrend(tt.history, tt.planYear, tt.planNum)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("computeAdmissionTrend() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsecutiveYears(t *testing.T) {
+	tests := []struct {
+		name  string
+		years []int32
+		want  bool
+	}{
+		{name: "空列表", years: nil, want: true},
+		{name: "单个年份", years: []int32{2024}, want: true},
+		{name: "逐年连续", years: []int32{2024, 2023, 2022}, want: true},
+		{name: "中间缺少年份", years: []int32{2024, 2022, 2021}, want: false},
+		{name: "年份从旧到新", years: []int32{2022, 2023, 2024}, want: false},
+		{name: "重复年份", years: []int32{2024, 2024}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := consecutiveYears(tt.years); got != tt.want {
+				t.Errorf("consecutiveYears(%v) = %v, want %v", tt.years, got, tt.want)
+			}
+		})
+	}
+}
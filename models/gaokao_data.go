@@ -2,43 +2,42 @@ package models
 
 import "database/sql"
 
-// GaokaoData matches the structure of your gaokao_data table
+// GaokaoData gaokao2025 表的一行，用于按列名映射查询结果（见 scanRows），查询只需选择用到的列
+// 枚举列在 ClickHouse 中为 Enum8、在关系型数据库中为整数，统一扫描为字符串
+// 上一年的录取数据（*_2024 列）不在此映射，查询时读取 admission_history
 type GaokaoData struct {
-	ID                              int32          `db:"id"`
-	SourceLocation                  string         `db:"source_location"`
-	PlanBatch                       string         `db:"plan_batch"`
-	PlanSubjectType                 string         `db:"plan_subject_type"`
-	PlanSubjectRestrictionBit       int32          `db:"plan_subject_restriction_bit"`
-	PlanSchoolCode                  string         `db:"plan_school_code"`
-	PlanMajorGroupCode              string         `db:"plan_major_group_code"`
-	PlanSchoolName                  string         `db:"plan_school_name"`
-	PlanMajorCode                   string         `db:"plan_major_code"`
-	PlanMajorName                   string         `db:"plan_major_name"`
-	PlanMajorRemark                 sql.NullString `db:"plan_major_remark"`
-	PlanDuration                    sql.NullString `db:"plan_duration"`
-	PlanTuitionFee                  sql.NullInt32  `db:"plan_tuition_fee"`
-	Admission2024PlanCount          int32          `db:"admission_2024_plan_count"`
-	PlanNewMajor                    uint8          `db:"plan_new_major"`
-	Admission2024MinScore           sql.NullInt32  `db:"admission_2024_min_score"`
-	Admission2024MinRank            sql.NullInt32  `db:"admission_2024_min_rank"`
-	Admission2024MajorGroupMinScore sql.NullInt32  `db:"admission_2024_major_group_min_score"`
-	Admission2024MajorGroupMinRank  sql.NullInt32  `db:"admission_2024_major_group_min_rank"`
-	Admission2023MajorGroupMinScore sql.NullInt32  `db:"admission_2023_major_group_min_score"`
-	Admission2023MajorGroupMinRank  sql.NullInt32  `db:"admission_2023_major_group_min_rank"`
-	Admission2023PlanCount          sql.NullInt32  `db:"admission_2023_plan_count"`
-	Admission2023MinScore           sql.NullInt32  `db:"admission_2023_min_score"`
-	Admission2023MinRank            sql.NullInt32  `db:"admission_2023_min_rank"`
-	Admission2022PlanCount          sql.NullInt32  `db:"admission_2022_plan_count"`
-	Admission2022MinScore           sql.NullInt32  `db:"admission_2022_min_score"`
-	Admission2022MinRank            sql.NullInt32  `db:"admission_2022_min_rank"`
-	Admission2021PlanCount          sql.NullInt32  `db:"admission_2021_plan_count"`
-	Admission2021MinScore           sql.NullInt32  `db:"admission_2021_min_score"`
-	Admission2021MinRank            sql.NullInt32  `db:"admission_2021_min_rank"`
-	SchoolInfoProvince              sql.NullString `db:"school_info_province"`
-	SchoolInfoCity                  sql.NullString `db:"school_info_city"`
-	SchoolInfoLevel                 sql.NullString `db:"school_info_level"`
-	SchoolInfoAffiliation           sql.NullString `db:"school_info_affiliation"`
-	SchoolInfoTagsList              []string       `db:"school_info_tags_list"` // ClickHouse Array(String)
-	SchoolInfoPublicPrivate         sql.NullString `db:"school_info_public_private"`
-	SchoolInfoLevelList             []string       `db:"school_info_level_list"` // ClickHouse Array(String)
+	ID                    int32          `db:"id"`
+	MajorID               string         `db:"major_id"`
+	SchoolCode            string         `db:"school_code"`
+	SchoolName            string         `db:"school_name"`
+	MajorGroupCode        string         `db:"major_group_code"`
+	MajorCode             string         `db:"major_code"`
+	MajorName             string         `db:"major_name"`
+	MajorCategory         string         `db:"major_category"`
+	MajorDescription      sql.NullString `db:"major_description"`
+	SourceProvince        string         `db:"source_province"`
+	SubjectCategory       string         `db:"subject_category"`
+	SubjectRequirementRaw string         `db:"subject_requirement_raw"`
+	RequirePhysics        bool           `db:"require_physics"`
+	RequireChemistry      bool           `db:"require_chemistry"`
+	RequireBiology        bool           `db:"require_biology"`
+	RequirePolitics       bool           `db:"require_politics"`
+	RequireHistory        bool           `db:"require_history"`
+	RequireGeography      bool           `db:"require_geography"`
+	TuitionFee            sql.NullString `db:"tuition_fee"`
+	StudyDuration         sql.NullInt32  `db:"study_duration"`
+	IsNewMajor            bool           `db:"is_new_major"`
+	AdmissionBatch        string         `db:"admission_batch"`
+	EnrollmentType        string         `db:"enrollment_type"`
+	// 招生计划年份及计划人数
+	EnrollmentPlanYear sql.NullInt32 `db:"enrollment_plan_year"`
+	EnrollmentPlan     sql.NullInt32 `db:"enrollment_plan"`
+	SchoolProvince     string        `db:"school_province"`
+	SchoolCity         string        `db:"school_city"`
+	SchoolType         string        `db:"school_type"`
+	SchoolOwnership    string        `db:"school_ownership"`
+	SchoolAuthority    string        `db:"school_authority"`
+	SchoolLevel        string        `db:"school_level"`
+	SchoolTags         []string      `db:"school_tags"` // ClickHouse 中为逗号分隔的字符串
+	EducationLevel     string        `db:"education_level"`
 }
@@ -4,10 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"gaokao-data-analysis/database"
 	"log/slog"
-	"strings"
+	"slices"
 	"time"
+
+	"gaokao-data-analysis/errcode"
 )
 
 // VoluntaryMajor 专业信息
@@ -34,8 +35,12 @@ type VoluntaryMajor struct {
 	StudyCost string `json:"study_cost"`
 	// 学制
 	StudyYear string `json:"study_year"`
-	// 信息年份
+	// 信息年份，即分数和位次所属的录取年份
 	Year string `json:"year"`
+	// 历年录取数据，年份从新到旧，招生计划年份只有计划人数
+	History []AdmissionYearStat `json:"history"`
+	// 历年录取趋势
+	Trend AdmissionTrend `json:"trend"`
 }
 
 // VoluntaryMajorGroup 专业组信息
@@ -46,74 +51,89 @@ type VoluntaryMajorGroup struct {
 	Major []VoluntaryMajor `json:"major"`
 	// 专业组概率，百分比
 	Probability int32 `json:"probability"`
-	// 策略，[0冲、1稳、2保]
+	// 策略，[0冲、1稳、2保]，只筛选了一个策略时为该策略，否则为1稳
 	Strategy int32 `json:"strategy"`
+	// 专业的策略筛选情况
+	Filter MajorStrategyFilter `json:"filter"`
+	// 专业组历年录取数据，年份从新到旧，招生计划年份只有计划人数
+	History []AdmissionYearStat `json:"history"`
+	// 专业组历年录取趋势，计划人数为组内专业之和
+	Trend AdmissionTrend `json:"trend"`
 }
 
-// GetMajorGroupsDetail 批量获取专业组详细信息
-func GetMajorGroupsDetail(ctx context.Context, schoolGroups []SchoolGroupPair, req *VoluntaryMajorGroupRequest) (map[string]*VoluntaryMajorGroup, error) {
-	startTime := time.Now()
+// MajorStrategyFilter 专业组内专业的策略筛选情况
+type MajorStrategyFilter struct {
+	// 筛选使用的策略，为空表示未筛选
+	Strategies []int32 `json:"strategies"`
+	// 返回的专业是否只包含所选策略的专业
+	Filtered bool `json:"filtered"`
+	// 没有符合所选策略的专业，按 include_all 返回了全部专业
+	FellBack bool `json:"fell_back"`
+	// 筛选前专业组内符合选科等条件的专业数
+	Total int `json:"total"`
+}
 
-	// 获取ClickHouse连接
-	db := database.GetClickHouse()
-	if db == nil {
-		return nil, fmt.Errorf("ClickHouse连接未初始化")
+// toVoluntaryMajor 将专业查询结果转换为专业信息，空值使用默认值
+// 最低分、最低位次来自历年录取数据，由 applyAdmissionHistory 设置
+func (row GaokaoData) toVoluntaryMajor() VoluntaryMajor {
+	major := VoluntaryMajor{
+		Code:      row.MajorCode,
+		ID:        row.ID,
+		Name:      row.MajorName,
+		PlanNum:   "0",
+		Remark:    row.MajorDescription.String,
+		StudyCost: "0",
+	}
+	if row.EnrollmentPlan.Valid {
+		major.PlanNum = fmt.Sprintf("%d", row.EnrollmentPlan.Int32)
 	}
+	if row.TuitionFee.Valid {
+		major.StudyCost = row.TuitionFee.String
+	}
+	if row.StudyDuration.Valid {
+		major.StudyYear = fmt.Sprintf("%d", row.StudyDuration.Int32)
+	}
+	return major
+}
 
-	// 初始化辅助器
-	enumMapper := NewEnumMapper()
-	profileManager := &ProfileManager{}
+// applyAdmissionHistory 设置专业的历年录取数据及趋势，并以最近一年的数据作为最低分、最低位次
+// planYear、planNum 为招生计划年份及计划人数
+func applyAdmissionHistory(major *VoluntaryMajor, history []AdmissionYearStat, planYear int32, planNum sql.NullInt32) {
+	major.History = history
 
-	// 应用用户档案信息
-	if err := profileManager.ApplyProfileToRequest(req.ProfileID, req); err != nil {
-		slog.Warn("应用用户档案失败", "error", err.Error())
+	var plan *int32
+	if planNum.Valid {
+		plan = &planNum.Int32
 	}
-
-	// 验证科目组合
-	if req.Subjects != "" {
-		if err := ValidateSubjects(req.Subjects); err != nil {
-			return nil, fmt.Errorf("科目验证失败: %w", err)
-		}
+	major.Trend = computeAdmissionTrend(history, planYear, plan)
+	latest := latestStat(history)
+	if latest == nil {
+		return
 	}
 
-	// 构建批量查询条件
-	if len(schoolGroups) == 0 {
-		return make(map[string]*VoluntaryMajorGroup), nil
+	major.Year = fmt.Sprintf("%d", latest.Year)
+	major.MinScore = *latest.MinScore
+	if latest.MinRank != nil {
+		major.MinRank = *latest.MinRank
 	}
+}
 
-	// 构建专业组查询 - 适配新表结构，支持批量查询
-	baseQuery := fmt.Sprintf(`SELECT 
-	school_code,
-	major_group_code,
-	id,
-	major_code as code,
-	major_name as name,
-	major_min_score_2024 as min_score,
-	major_min_rank_2024 as min_rank,
-	enrollment_plan_2024 as plan_num,
-	tuition_fee as study_cost,
-	study_duration as study_year,
-	major_description as remark
-FROM %s
-WHERE (school_code, major_group_code) IN (`, TABLE)
-
-	// 构建 IN 条件的参数占位符
-	var inConditions []string
-	var inArgs []interface{}
-	for _, sg := range schoolGroups {
-		inConditions = append(inConditions, "(?, ?)")
-		inArgs = append(inArgs, sg.SchoolCode, sg.GroupCode)
+// majorProbability 计算专业的录取概率和策略，没有分数数据时默认50%、稳
+func majorProbability(userScore, minScore int32) (int32, int32) {
+	if userScore > 0 && minScore > 0 {
+		return CalculateProbability(userScore, minScore), GetStrategy(userScore, minScore)
 	}
+	return 50, 1
+}
 
-	baseQuery += strings.Join(inConditions, ", ") + ")"
-
-	queryBuilder := NewQueryBuilder(baseQuery)
-	queryBuilder.args = append(queryBuilder.args, inArgs...)
+// buildMajorFilter 根据请求构建专业查询条件
+func buildMajorFilter(em *EnumMapper, req *VoluntaryMajorGroupRequest) (*MajorFilter, error) {
+	filter := &MajorFilter{}
 
 	// 处理省份条件
 	if req.Province != "" {
-		if provinceVal, exists := enumMapper.MapProvince(req.Province); exists {
-			queryBuilder.AddCondition("source_province = ?", provinceVal)
+		if provinceVal, exists := em.MapProvince(req.Province); exists {
+			filter.SourceProvince = provinceVal
 		}
 	}
 
@@ -121,161 +141,103 @@ WHERE (school_code, major_group_code) IN (`, TABLE)
 	if req.Subjects != "" {
 		subjectFilter, err := ParseSubjects(req.Subjects)
 		if err != nil {
-			return nil, fmt.Errorf("解析科目失败: %w", err)
+			return nil, errcode.New(errcode.InvalidSubjects, err.Error())
 		}
-
-		// 添加科目相关条件
-		subjectConditions, subjectArgs := subjectFilter.BuildSubjectConditions()
-		queryBuilder.AddConditions(subjectConditions, subjectArgs)
+		filter.Subjects = subjectFilter
 	}
 
-	// 构建最终查询
-	finalQuery, finalArgs := queryBuilder.Build()
-	finalQuery += " ORDER BY school_code, major_group_code, major_name ASC"
+	return filter, nil
+}
 
-	slog.Info("批量查询专业组信息",
-		"schoolGroupCount", len(schoolGroups),
-		"query", finalQuery,
-		"args", finalArgs,
-	)
+// GetMajorGroups 批量获取专业组详细信息，单个专业组与院校优先推荐共用该查询
+// 返回结果按 (院校代码, 专业组代码) 索引，没有符合条件专业的专业组不包含在结果中
+func GetMajorGroups(ctx context.Context, schoolGroups []SchoolGroupPair, req *VoluntaryMajorGroupRequest) (map[SchoolGroupPair]*VoluntaryMajorGroup, error) {
+	startTime := time.Now()
+	result := make(map[SchoolGroupPair]*VoluntaryMajorGroup)
 
-	// 执行查询
-	majorRows, err := db.QueryContext(ctx, finalQuery, finalArgs...)
+	// 获取录取数据查询实现
+	repo, err := GetAdmissionRepository()
 	if err != nil {
-		slog.Error("批量查询专业信息失败", "error", err.Error())
-		return nil, fmt.Errorf("批量查询专业信息失败: %w", err)
+		return nil, err
 	}
-	defer majorRows.Close()
-
-	// 处理结果 - 按学校代码+专业组代码分组
-	result := make(map[string]*VoluntaryMajorGroup)
-	groupMajorsMap := make(map[string][]VoluntaryMajor)
-	groupProbabilityMap := make(map[string][]int32)
-
-	for majorRows.Next() {
-		var schoolCode, groupCode string
-		var id int32
-		var code, name string
-		var minScore, minRank sql.NullInt32
-		var planNum sql.NullInt32
-		var studyCost sql.NullString
-		var studyYear sql.NullInt32
-		var remark sql.NullString
-
-		if err := majorRows.Scan(&schoolCode, &groupCode, &id, &code, &name, &minScore, &minRank, &planNum, &studyCost, &studyYear, &remark); err != nil {
-			slog.Error("扫描专业信息失败", "error", err.Error())
-			continue
-		}
 
-		// 计算每个专业的录取概率和策略
-		var probability int32 = 50 // 默认50%
-		var strategy int32 = 1     // 默认稳
+	// 初始化辅助器
+	enumMapper := NewEnumMapper()
+	profileManager := &ProfileManager{}
 
-		// 如果有用户分数，计算具体概率
-		if req.Score > 0 && minScore.Valid {
-			probability = CalculateProbability(req.Score, minScore.Int32)
-			strategy = GetStrategy(req.Score, minScore.Int32)
-		}
+	// 应用用户档案信息
+	if err := profileManager.ApplyProfileToRequest(ctx, req.ProfileID, req.UserID, req); err != nil {
+		return nil, err
+	}
 
-		// 创建专业信息
-		major := VoluntaryMajor{
-			Code: code,
-			ID:   id,
-			Name: name,
-			MinScore: func() int32 {
-				if minScore.Valid {
-					return minScore.Int32
-				}
-				return 0
-			}(),
-			MinRank: func() int32 {
-				if minRank.Valid {
-					return minRank.Int32
-				}
-				return 0
-			}(),
-			PlanNum: func() string {
-				if planNum.Valid {
-					return fmt.Sprintf("%d", planNum.Int32)
-				}
-				return "0"
-			}(),
-			Probability: probability,
-			Remark: func() string {
-				if remark.Valid {
-					return remark.String
-				}
-				return ""
-			}(),
-			Strategy: strategy,
-			StudyCost: func() string {
-				if studyCost.Valid {
-					return studyCost.String
-				}
-				return "0"
-			}(),
-			StudyYear: func() string {
-				if studyYear.Valid {
-					return fmt.Sprintf("%d", studyYear.Int32)
-				}
-				return ""
-			}(),
-			Year: "2024",
+	// 验证科目组合
+	if req.Subjects != "" {
+		if err := ValidateSubjects(req.Subjects); err != nil {
+			return nil, errcode.New(errcode.InvalidSubjects, err.Error())
 		}
+	}
 
-		// 按学校代码+专业组代码分组
-		groupKey := fmt.Sprintf("%s-%s", schoolCode, groupCode)
-		groupMajorsMap[groupKey] = append(groupMajorsMap[groupKey], major)
-		groupProbabilityMap[groupKey] = append(groupProbabilityMap[groupKey], probability)
+	strategies, err := req.strategyFilter()
+	if err != nil {
+		return nil, err
 	}
 
-	// 创建专业组信息
-	for groupKey, majors := range groupMajorsMap {
-		probabilities := groupProbabilityMap[groupKey]
-
-		// 计算专业组的平均概率
-		var groupProbability int32 = 0
-		if len(probabilities) > 0 {
-			var sum int32 = 0
-			for _, prob := range probabilities {
-				sum += prob
-			}
-			groupProbability = sum / int32(len(probabilities))
-		}
+	if len(schoolGroups) == 0 {
+		return result, nil
+	}
 
-		// 根据策略参数筛选显示的专业
-		filteredMajors := majors
-		if req.Strategy > 0 {
-			var strategyMajors []VoluntaryMajor
-			for _, major := range majors {
-				if major.Strategy == req.Strategy {
-					strategyMajors = append(strategyMajors, major)
-				}
-			}
-
-			// 如果筛选后没有专业，则保留全部
-			if len(strategyMajors) > 0 {
-				filteredMajors = strategyMajors
-			}
-		}
+	filter, err := buildMajorFilter(enumMapper, req)
+	if err != nil {
+		return nil, err
+	}
 
-		// 从 groupKey 中解析出 groupCode
-		parts := strings.Split(groupKey, "-")
-		if len(parts) >= 2 {
-			groupCode := parts[1]
+	// 执行查询
+	majorRows, err := repo.ListMajors(ctx, schoolGroups, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "批量查询专业信息失败", "error", err.Error(), "schoolGroupCount", len(schoolGroups))
+		return nil, fmt.Errorf("批量查询专业信息失败: %w", err)
+	}
+
+	// 查询历年录取数据
+	history, err := loadAdmissionHistory(ctx, repo, schoolGroups, filter.SourceProvince, req.HistoryYears)
+	if err != nil {
+		return nil, err
+	}
 
-			majorGroup := &VoluntaryMajorGroup{
-				GroupCode:   groupCode,
-				Major:       filteredMajors,
-				Probability: groupProbability,
-				Strategy:    req.Strategy,
-			}
+	// 按院校代码+专业组代码分组，保持查询结果的专业顺序
+	groupMajors := make(map[SchoolGroupPair][]VoluntaryMajor)
+	for _, row := range majorRows {
+		key := SchoolGroupPair{SchoolCode: row.SchoolCode, GroupCode: row.MajorGroupCode}
 
-			result[groupKey] = majorGroup
+		// 创建专业信息
+		major := row.toVoluntaryMajor()
+
+		// 使用历年录取数据中最近一年的分数和位次
+		applyAdmissionHistory(&major, history.major(key.SchoolCode, key.GroupCode, row.MajorCode), row.EnrollmentPlanYear.Int32, row.EnrollmentPlan)
+
+		// 计算每个专业的录取概率和策略
+		major.Probability, major.Strategy = majorProbability(req.Score, major.MinScore)
+
+		groupMajors[key] = append(groupMajors[key], major)
+	}
+
+	// 创建专业组信息
+	for key, majors := range groupMajors {
+		groupHistory := history.group(key.SchoolCode, key.GroupCode)
+		filteredMajors, strategyFilter := filterMajorsByStrategy(majors, strategies, req.IncludeAll)
+		// 录取概率和计划人数趋势描述整个专业组，使用筛选前的全部专业
+		result[key] = &VoluntaryMajorGroup{
+			GroupCode:   key.GroupCode,
+			Major:       filteredMajors,
+			Probability: averageProbability(majors),
+			Strategy:    groupStrategy(strategies),
+			Filter:      strategyFilter,
+			History:     groupHistory,
+			Trend:       computeGroupTrend(groupHistory, majors),
 		}
 	}
 
-	slog.Info("批量获取专业组信息完成",
+	slog.InfoContext(ctx, "批量获取专业组信息完成",
 		"schoolGroupCount", len(schoolGroups),
 		"resultCount", len(result),
 		"duration", time.Since(startTime).String(),
@@ -284,202 +246,72 @@ WHERE (school_code, major_group_code) IN (`, TABLE)
 	return result, nil
 }
 
-// GetMajorGroupDetail 获取专业组详细信息（保留原函数以兼容性）
+// GetMajorGroupDetail 获取单个专业组详细信息，没有符合条件的专业时返回空专业组
 func GetMajorGroupDetail(ctx context.Context, req *VoluntaryMajorGroupRequest) (*VoluntaryMajorGroup, error) {
-	startTime := time.Now()
-
-	// 获取ClickHouse连接
-	db := database.GetClickHouse()
-	if db == nil {
-		return nil, fmt.Errorf("ClickHouse连接未初始化")
-	}
-
-	// 初始化辅助器
-	enumMapper := NewEnumMapper()
-	profileManager := &ProfileManager{}
-
-	// 应用用户档案信息
-	if err := profileManager.ApplyProfileToRequest(req.ProfileID, req); err != nil {
-		slog.Warn("应用用户档案失败", "error", err.Error())
+	key := SchoolGroupPair{SchoolCode: req.SchoolCode, GroupCode: req.GroupCode}
+	groups, err := GetMajorGroups(ctx, []SchoolGroupPair{key}, req)
+	if err != nil {
+		return nil, err
 	}
 
-	// 验证科目组合
-	if req.Subjects != "" {
-		if err := ValidateSubjects(req.Subjects); err != nil {
-			return nil, fmt.Errorf("科目验证失败: %w", err)
-		}
+	if majorGroup, exists := groups[key]; exists {
+		return majorGroup, nil
 	}
 
-	// 构建专业组查询 - 适配新表结构
-	baseQuery := fmt.Sprintf(`SELECT 
-	id,
-	major_code as code,
-	major_name as name,
-	major_min_score_2024 as min_score,
-	major_min_rank_2024 as min_rank,
-	enrollment_plan_2024 as plan_num,
-	tuition_fee as study_cost,
-	study_duration as study_year,
-	major_description as remark
-FROM %s
-WHERE school_code = ? AND major_group_code = ?
-`, TABLE)
-
-	queryBuilder := NewQueryBuilder(baseQuery)
-	queryBuilder.args = append(queryBuilder.args, req.SchoolCode, req.GroupCode)
+	// strategyFilter 已在 GetMajorGroups 中校验
+	strategies, _ := req.strategyFilter()
+	_, strategyFilter := filterMajorsByStrategy(nil, strategies, req.IncludeAll)
+	return &VoluntaryMajorGroup{
+		GroupCode: req.GroupCode,
+		Major:     []VoluntaryMajor{},
+		Strategy:  groupStrategy(strategies),
+		Filter:    strategyFilter,
+	}, nil
+}
 
-	// 处理省份条件
-	if req.Province != "" {
-		if provinceVal, exists := enumMapper.MapProvince(req.Province); exists {
-			queryBuilder.AddCondition("source_province = ?", provinceVal)
-		}
+// averageProbability 计算专业组的整体概率（所有专业概率的平均值）
+func averageProbability(majors []VoluntaryMajor) int32 {
+	if len(majors) == 0 {
+		return 0
 	}
 
-	// 处理科目条件
-	if req.Subjects != "" {
-		subjectFilter, err := ParseSubjects(req.Subjects)
-		if err != nil {
-			return nil, fmt.Errorf("解析科目失败: %w", err)
-		}
-
-		// 添加科目相关条件
-		subjectConditions, subjectArgs := subjectFilter.BuildSubjectConditions()
-		queryBuilder.AddConditions(subjectConditions, subjectArgs)
+	var sum int32
+	for _, major := range majors {
+		sum += major.Probability
 	}
+	return sum / int32(len(majors))
+}
 
-	// 构建最终查询
-	finalQuery, finalArgs := queryBuilder.Build()
-	finalQuery += " ORDER BY major_name ASC"
-
-	slog.Info("查询专业组信息",
-		"schoolCode", req.SchoolCode,
-		"groupCode", req.GroupCode,
-		"query", finalQuery,
-		"args", finalArgs,
-	)
-
-	// 执行查询
-	majorRows, err := db.QueryContext(ctx, finalQuery, finalArgs...)
-	if err != nil {
-		slog.Error("查询专业信息失败", "error", err.Error(), "schoolCode", req.SchoolCode, "groupCode", req.GroupCode)
-		return nil, fmt.Errorf("查询专业信息失败: %w", err)
+// filterMajorsByStrategy 只保留所选策略的专业
+// 没有符合的专业时返回空列表，includeAll 为 true 时改为返回全部专业
+func filterMajorsByStrategy(majors []VoluntaryMajor, strategies []int32, includeAll bool) ([]VoluntaryMajor, MajorStrategyFilter) {
+	result := MajorStrategyFilter{
+		Strategies: strategies,
+		Total:      len(majors),
 	}
-	defer majorRows.Close()
-
-	// 处理结果
-	var majors []VoluntaryMajor
-	var groupProbability int32 = 0
-
-	for majorRows.Next() {
-		var id int32
-		var code, name string
-		var minScore, minRank sql.NullInt32
-		var planNum sql.NullInt32
-		var studyCost sql.NullString
-		var studyYear sql.NullInt32
-		var remark sql.NullString
-
-		if err := majorRows.Scan(&id, &code, &name, &minScore, &minRank, &planNum, &studyCost, &studyYear, &remark); err != nil {
-			slog.Error("扫描专业信息失败", "error", err.Error())
-			continue
-		}
-
-		// 计算每个专业的录取概率和策略
-		var probability int32 = 50 // 默认50%
-		var strategy int32 = 1     // 默认稳
-
-		// 如果有用户分数，计算具体概率
-		if req.Score > 0 && minScore.Valid {
-			probability = CalculateProbability(req.Score, minScore.Int32)
-			strategy = GetStrategy(req.Score, minScore.Int32)
-		}
-
-		// 更新专业组的整体概率（取平均值）
-		groupProbability += probability
-
-		// 创建专业信息
-		major := VoluntaryMajor{
-			Code: code,
-			ID:   id,
-			Name: name,
-			MinScore: func() int32 {
-				if minScore.Valid {
-					return minScore.Int32
-				}
-				return 0
-			}(),
-			MinRank: func() int32 {
-				if minRank.Valid {
-					return minRank.Int32
-				}
-				return 0
-			}(),
-			PlanNum: func() string {
-				if planNum.Valid {
-					return fmt.Sprintf("%d", planNum.Int32)
-				}
-				return "0"
-			}(),
-			Probability: probability,
-			Remark: func() string {
-				if remark.Valid {
-					return remark.String
-				}
-				return ""
-			}(),
-			Strategy: strategy,
-			StudyCost: func() string {
-				if studyCost.Valid {
-					return studyCost.String
-				}
-				return "0"
-			}(),
-			StudyYear: func() string {
-				if studyYear.Valid {
-					return fmt.Sprintf("%d", studyYear.Int32)
-				}
-				return ""
-			}(),
-			Year: "2024",
-		}
-
-		majors = append(majors, major)
+	if len(strategies) == 0 {
+		return majors, result
 	}
 
-	// 计算专业组的平均概率
-	if len(majors) > 0 {
-		groupProbability = groupProbability / int32(len(majors))
-
-		// 根据策略参数筛选显示的专业
-		if req.Strategy > 0 {
-			var filteredMajors []VoluntaryMajor
-			for _, major := range majors {
-				if major.Strategy == req.Strategy {
-					filteredMajors = append(filteredMajors, major)
-				}
-			}
-
-			// 如果筛选后没有专业，则保留全部
-			if len(filteredMajors) > 0 {
-				majors = filteredMajors
-			}
+	filtered := []VoluntaryMajor{}
+	for _, major := range majors {
+		if slices.Contains(strategies, major.Strategy) {
+			filtered = append(filtered, major)
 		}
 	}
-
-	// 创建专业组信息
-	majorGroup := &VoluntaryMajorGroup{
-		GroupCode:   req.GroupCode,
-		Major:       majors,
-		Probability: groupProbability,
-		Strategy:    req.Strategy,
+	if len(filtered) == 0 && includeAll && len(majors) > 0 {
+		result.FellBack = true
+		return majors, result
 	}
 
-	slog.Info("获取专业组信息完成",
-		"schoolCode", req.SchoolCode,
-		"groupCode", req.GroupCode,
-		"majorCount", len(majors),
-		"duration", time.Since(startTime).String(),
-	)
+	result.Filtered = true
+	return filtered, result
+}
 
-	return majorGroup, nil
+// groupStrategy 返回专业组的策略，只筛选了一个策略时为该策略，否则默认稳
+func groupStrategy(strategies []int32) int32 {
+	if len(strategies) == 1 {
+		return strategies[0]
+	}
+	return 1
 }
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterMajorsByStrategy(t *testing.T) {
+	rush := VoluntaryMajor{Code: "01", Strategy: 0}
+	match := VoluntaryMajor{Code: "02", Strategy: 1}
+	safe := VoluntaryMajor{Code: "03", Strategy: 2}
+	all := []VoluntaryMajor{rush, match, safe}
+
+	tests := []struct {
+		name       string
+		majors     []VoluntaryMajor
+		strategies []int32
+		includeAll bool
+		want       []VoluntaryMajor
+		wantFilter MajorStrategyFilter
+	}{
+		{
+			name:       "未选择策略时返回全部专业",
+			majors:     all,
+			want:       all,
+			wantFilter: MajorStrategyFilter{Total: 3},
+		},
+		{
+			name:       "只保留所选策略的专业",
+			majors:     all,
+			strategies: []int32{1},
+			want:       []VoluntaryMajor{match},
+			wantFilter: MajorStrategyFilter{Strategies: []int32{1}, Filtered: true, Total: 3},
+		},
+		{
+			name:       "多个策略保持原有顺序",
+			majors:     all,
+			strategies: []int32{2, 0},
+			want:       []VoluntaryMajor{rush, safe},
+			wantFilter: MajorStrategyFilter{Strategies: []int32{2, 0}, Filtered: true, Total: 3},
+		},
+		{
+			name:       "没有符合的专业时返回空列表",
+			majors:     []VoluntaryMajor{rush, match},
+			strategies: []int32{2},
+			want:       []VoluntaryMajor{},
+			wantFilter: MajorStrategyFilter{Strategies: []int32{2}, Filtered: true, Total: 2},
+		},
+		{
+			name:       "没有符合的专业且 includeAll 时返回全部专业",
+			majors:     []VoluntaryMajor{rush, match},
+			strategies: []int32{2},
+			includeAll: true,
+			want:       []VoluntaryMajor{rush, match},
+			wantFilter: MajorStrategyFilter{Strategies: []int32{2}, FellBack: true, Total: 2},
+		},
+		{
+			name:       "有符合的专业时忽略 includeAll",
+			majors:     all,
+			strategies: []int32{0},
+			includeAll: true,
+			want:       []VoluntaryMajor{rush},
+			wantFilter: MajorStrategyFilter{Strategies: []int32{0}, Filtered: true, Total: 3},
+		},
+		{
+			name:       "专业组没有专业时不回退",
+			strategies: []int32{1},
+			includeAll: true,
+			want:       []VoluntaryMajor{},
+			wantFilter: MajorStrategyFilter{Strategies: []int32{1}, Filtered: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotFilter := filterMajorsByStrategy(tt.majors, tt.strategies, tt.includeAll)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterMajorsByStrategy() majors = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotFilter, tt.wantFilter) {
+				t.Errorf("filterMajorsByStrategy() filter = %+v, want %+v", gotFilter, tt.wantFilter)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gaokao-data-analysis/database"
+	"gaokao-data-analysis/errcode"
+
+	"gorm.io/gorm"
+)
+
+// Profile member relations
+const (
+	RelationCounselor = "counselor"
+	RelationParent    = "parent"
+)
+
+// ProfileAccess 用户对档案的访问级别
+type ProfileAccess int
+
+const (
+	ProfileAccessNone  ProfileAccess = iota // 无权访问
+	ProfileAccessRead                       // 只读（家长）
+	ProfileAccessWrite                      // 可读写（本人、规划师、管理员）
+)
+
+// ErrProfileNotClaimed is returned when members of a guest profile are managed before it has been claimed
+var ErrProfileNotClaimed = errcode.New(errcode.ProfileForbidden, "档案尚未认领，认领后才能管理成员")
+
+// ProfileMember links a counselor or parent account to a student's profile
+type ProfileMember struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	ProfileID string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_profile_member" json:"profile_id"`
+	UserID    string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_profile_member;index" json:"user_id"`
+	Relation  string    `gorm:"type:varchar(20);not null" json:"relation"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddProfileMemberRequest represents the API request to grant a counselor or parent access to a profile
+type AddProfileMemberRequest struct {
+	// Account is the phone number or email of the counselor or parent
+	Account  string `json:"account" binding:"required"`
+	Relation string `json:"relation" binding:"required,oneof=counselor parent"`
+}
+
+// RosterEntry is a summary of one student profile in a counselor's roster
+type RosterEntry struct {
+	ProfileID  string    `json:"profile_id"`
+	Username   string    `json:"username"`
+	Province   string    `json:"province"`
+	Subjects   []string  `json:"subjects"`
+	Score      int32     `json:"score"`
+	Rank       int32     `json:"rank"`
+	Completion int32     `json:"completion"` // 档案填写完成度，百分比
+	Claimed    bool      `json:"claimed"`    // 学生是否已认领档案
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ==================== Access Control ====================
+
+// GetProfileAccess 计算用户（匿名时为空）对档案的访问级别
+// 未归属且没有成员的档案为游客档案，持有ID即可读写；规划师代建的档案在学生认领前只有成员可以访问
+func GetProfileAccess(ctx context.Context, profile *UserProfile, userID string) (ProfileAccess, error) {
+	db := database.GetDB().WithContext(ctx)
+
+	if userID != "" {
+		if profile.OwnerID != nil && *profile.OwnerID == userID {
+			return ProfileAccessWrite, nil
+		}
+
+		user, err := GetUserByID(ctx, userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return ProfileAccessNone, err
+		}
+		if user != nil && user.HasRole(RoleAdmin) {
+			return ProfileAccessWrite, nil
+		}
+
+		var member ProfileMember
+		result := db.Where("profile_id = ? AND user_id = ?", profile.ID, userID).Limit(1).Find(&member)
+		if result.Error != nil {
+			return ProfileAccessNone, result.Error
+		}
+		if result.RowsAffected > 0 {
+			if member.Relation == RelationCounselor {
+				return ProfileAccessWrite, nil
+			}
+			return ProfileAccessRead, nil
+		}
+	}
+
+	if profile.OwnerID != nil {
+		return ProfileAccessNone, nil
+	}
+
+	var memberCount int64
+	if err := db.Model(&ProfileMember{}).Where("profile_id = ?", profile.ID).Count(&memberCount).Error; err != nil {
+		return ProfileAccessNone, err
+	}
+	if memberCount > 0 {
+		return ProfileAccessNone, nil
+	}
+	return ProfileAccessWrite, nil
+}
+
+// CheckMemberManager 校验用户能否添加或移除档案成员：档案本人、管理员或档案的规划师
+// 游客档案的写权限来自持有档案ID，不能用于管理成员，否则持有ID的人可以把自己加为规划师接管档案，或移除档案的规划师
+func CheckMemberManager(ctx context.Context, profile *UserProfile, userID string) error {
+	db := database.GetDB().WithContext(ctx)
+
+	if userID != "" {
+		if profile.OwnerID != nil && *profile.OwnerID == userID {
+			return nil
+		}
+
+		user, err := GetUserByID(ctx, userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if user != nil && user.HasRole(RoleAdmin) {
+			return nil
+		}
+
+		var counselorCount int64
+		if err := db.Model(&ProfileMember{}).
+			Where("profile_id = ? AND user_id = ? AND relation = ?", profile.ID, userID, RelationCounselor).
+			Count(&counselorCount).Error; err != nil {
+			return err
+		}
+		if counselorCount > 0 {
+			return nil
+		}
+	}
+
+	if profile.OwnerID == nil {
+		var memberCount int64
+		if err := db.Model(&ProfileMember{}).Where("profile_id = ?", profile.ID).Count(&memberCount).Error; err != nil {
+			return err
+		}
+		if memberCount == 0 {
+			return ErrProfileNotClaimed
+		}
+	}
+	return ErrProfileForbidden
+}
+
+// ==================== Database Operations ====================
+
+// AddProfileMember grants a counselor or parent account access to a profile on behalf of userID
+func AddProfileMember(ctx context.Context, profile *UserProfile, userID string, request *AddProfileMemberRequest) (*ProfileMember, error) {
+	if err := CheckMemberManager(ctx, profile, userID); err != nil {
+		return nil, err
+	}
+
+	user, err := GetUserByAccount(ctx, request.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	// 关系需要与账号角色匹配，管理员可以作为规划师加入
+	switch request.Relation {
+	case RelationCounselor:
+		if !user.HasRole(RoleCounselor, RoleAdmin) {
+			return nil, errcode.New(errcode.InvalidMember, "该账号不是规划师")
+		}
+	case RelationParent:
+		if !user.HasRole(RoleParent) {
+			return nil, errcode.New(errcode.InvalidMember, "该账号不是家长")
+		}
+	}
+
+	member := &ProfileMember{
+		ProfileID: profile.ID,
+		UserID:    user.ID,
+		Relation:  request.Relation,
+	}
+	db := database.GetDB().WithContext(ctx)
+	result := db.Where(ProfileMember{ProfileID: profile.ID, UserID: user.ID}).
+		Assign(ProfileMember{Relation: request.Relation}).
+		FirstOrCreate(member)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return member, nil
+}
+
+// RemoveProfileMember revokes memberID's access to a profile on behalf of userID
+func RemoveProfileMember(ctx context.Context, profile *UserProfile, userID, memberID string) error {
+	if err := CheckMemberManager(ctx, profile, userID); err != nil {
+		return err
+	}
+
+	db := database.GetDB().WithContext(ctx)
+	return db.Where("profile_id = ? AND user_id = ?", profile.ID, memberID).Delete(&ProfileMember{}).Error
+}
+
+// ListProfileMembers lists the counselors and parents of a profile
+func ListProfileMembers(ctx context.Context, profileID string) ([]ProfileMember, error) {
+	var members []ProfileMember
+	db := database.GetDB().WithContext(ctx)
+	if result := db.Where("profile_id = ?", profileID).Find(&members); result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
+
+// ListManagedProfiles lists the profiles a counselor manages; admins get every profile
+func ListManagedProfiles(ctx context.Context, user *User) ([]UserProfile, error) {
+	var profiles []UserProfile
+	db := database.GetDB().WithContext(ctx)
+	query := db.Order("updated_at DESC")
+	if !user.HasRole(RoleAdmin) {
+		query = query.Where("id IN (?)",
+			db.Model(&ProfileMember{}).Select("profile_id").
+				Where("user_id = ? AND relation = ?", user.ID, RelationCounselor))
+	}
+	if result := query.Find(&profiles); result.Error != nil {
+		return nil, result.Error
+	}
+	return profiles, nil
+}
+
+// ListMemberProfiles lists the profiles a user has been added to as a counselor or parent
+func ListMemberProfiles(ctx context.Context, userID string) ([]UserProfile, error) {
+	var profiles []UserProfile
+	db := database.GetDB().WithContext(ctx)
+	result := db.Where("id IN (?)", db.Model(&ProfileMember{}).Select("profile_id").Where("user_id = ?", userID)).
+		Order("updated_at DESC").
+		Find(&profiles)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return profiles, nil
+}
+
+// GetCounselorRoster summarises the profiles a counselor manages
+func GetCounselorRoster(ctx context.Context, user *User) ([]RosterEntry, error) {
+	profiles, err := ListManagedProfiles(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	roster := make([]RosterEntry, 0, len(profiles))
+	for i := range profiles {
+		profile := &profiles[i]
+		roster = append(roster, RosterEntry{
+			ProfileID:  profile.ID,
+			Username:   profile.Username,
+			Province:   profile.Province,
+			Subjects:   profile.Subjects,
+			Score:      profile.Score,
+			Rank:       profile.Rank,
+			Completion: profile.Completion(),
+			Claimed:    profile.OwnerID != nil,
+			UpdatedAt:  profile.UpdatedAt,
+		})
+	}
+	return roster, nil
+}
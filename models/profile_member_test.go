@@ -0,0 +1,120 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gaokao-data-analysis/database"
+)
+
+// profileAccessFixture 访问控制测试使用的用户和档案
+type profileAccessFixture struct {
+	owner, admin, counselor, parent, stranger *User
+	// owned 学生本人的档案，counselor 和 parent 为成员
+	owned *UserProfile
+	// guest 游客档案，没有归属和成员
+	guest *UserProfile
+	// managed 规划师代建、学生尚未认领的档案
+	managed *UserProfile
+}
+
+func newProfileAccessFixture(t *testing.T) *profileAccessFixture {
+	t.Helper()
+	setupTestDB(t)
+
+	f := &profileAccessFixture{
+		owner:     createTestUser(t, RoleStudent),
+		admin:     createTestUser(t, RoleAdmin),
+		counselor: createTestUser(t, RoleCounselor),
+		parent:    createTestUser(t, RoleParent),
+		stranger:  createTestUser(t, RoleStudent),
+	}
+	f.owned = createTestProfile(t, f.owner.ID)
+	f.guest = createTestProfile(t, "")
+	f.managed = createTestProfile(t, f.counselor.ID)
+
+	for _, member := range []ProfileMember{
+		{ProfileID: f.owned.ID, UserID: f.counselor.ID, Relation: RelationCounselor},
+		{ProfileID: f.owned.ID, UserID: f.parent.ID, Relation: RelationParent},
+	} {
+		if err := database.GetDB().Create(&member).Error; err != nil {
+			t.Fatalf("create member error = %v", err)
+		}
+	}
+	return f
+}
+
+// testUserID 返回用户 ID，nil 表示匿名
+func testUserID(u *User) string {
+	if u == nil {
+		return ""
+	}
+	return u.ID
+}
+
+func TestGetProfileAccess(t *testing.T) {
+	f := newProfileAccessFixture(t)
+
+	tests := []struct {
+		name    string
+		profile *UserProfile
+		user    *User
+		want    ProfileAccess
+	}{
+		{name: "本人可读写", profile: f.owned, user: f.owner, want: ProfileAccessWrite},
+		{name: "管理员可读写", profile: f.owned, user: f.admin, want: ProfileAccessWrite},
+		{name: "规划师成员可读写", profile: f.owned, user: f.counselor, want: ProfileAccessWrite},
+		{name: "家长成员只读", profile: f.owned, user: f.parent, want: ProfileAccessRead},
+		{name: "其他用户无权访问", profile: f.owned, user: f.stranger, want: ProfileAccessNone},
+		{name: "匿名用户无权访问已归属档案", profile: f.owned, want: ProfileAccessNone},
+		{name: "匿名用户可读写游客档案", profile: f.guest, want: ProfileAccessWrite},
+		{name: "其他用户可读写游客档案", profile: f.guest, user: f.stranger, want: ProfileAccessWrite},
+		{name: "代建档案的规划师可读写", profile: f.managed, user: f.counselor, want: ProfileAccessWrite},
+		{name: "认领前其他用户无权访问代建档案", profile: f.managed, user: f.stranger, want: ProfileAccessNone},
+		{name: "认领前匿名用户无权访问代建档案", profile: f.managed, want: ProfileAccessNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetProfileAccess(context.Background(), tt.profile, testUserID(tt.user))
+			if err != nil {
+				t.Fatalf("GetProfileAccess() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetProfileAccess() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMemberManager(t *testing.T) {
+	f := newProfileAccessFixture(t)
+
+	tests := []struct {
+		name    string
+		profile *UserProfile
+		user    *User
+		wantErr error
+	}{
+		{name: "本人可以管理成员", profile: f.owned, user: f.owner},
+		{name: "管理员可以管理成员", profile: f.owned, user: f.admin},
+		{name: "规划师成员可以管理成员", profile: f.owned, user: f.counselor},
+		{name: "家长成员不能管理成员", profile: f.owned, user: f.parent, wantErr: ErrProfileForbidden},
+		{name: "其他用户不能管理成员", profile: f.owned, user: f.stranger, wantErr: ErrProfileForbidden},
+		{name: "匿名用户不能管理成员", profile: f.owned, wantErr: ErrProfileForbidden},
+		{name: "游客档案认领前不能管理成员", profile: f.guest, wantErr: ErrProfileNotClaimed},
+		{name: "持有游客档案的用户认领前不能管理成员", profile: f.guest, user: f.stranger, wantErr: ErrProfileNotClaimed},
+		{name: "管理员可以管理游客档案的成员", profile: f.guest, user: f.admin},
+		{name: "代建档案的规划师可以管理成员", profile: f.managed, user: f.counselor},
+		{name: "其他用户不能管理代建档案的成员", profile: f.managed, user: f.stranger, wantErr: ErrProfileForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckMemberManager(context.Background(), tt.profile, testUserID(tt.user)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckMemberManager() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
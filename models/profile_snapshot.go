@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gaokao-data-analysis/database"
+)
+
+// ProfileSnapshot records a student's score and rank at one exam (一模/二模/三模/高考)
+type ProfileSnapshot struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	ProfileID string     `gorm:"type:varchar(36);not null;index" json:"profile_id"`
+	Label     string     `gorm:"type:varchar(50);not null" json:"label"`
+	ExamDate  *time.Time `json:"exam_date,omitempty"`
+	Score     int32      `gorm:"not null" json:"score"`
+	// Rank is the rank reported by the exam itself (e.g. city-wide mock exam rank), optional
+	Rank      int32      `json:"rank"`
+	Subjects  StringList `gorm:"type:json;not null" json:"subjects"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ProfileSnapshotRequest represents the API request to record a snapshot
+type ProfileSnapshotRequest struct {
+	Label string `json:"label" binding:"required"`
+	// ExamDate in YYYY-MM-DD format
+	ExamDate string `json:"exam_date,omitempty"`
+	Score    int32  `json:"score" binding:"required,gt=0"`
+	Rank     int32  `json:"rank,omitempty" binding:"omitempty,gt=0"`
+	// Subjects defaults to the profile's subjects when omitted
+	Subjects []string `json:"subjects,omitempty"`
+}
+
+// ProfileTrendItem is one point of a profile's score trend
+type ProfileTrendItem struct {
+	ProfileSnapshot
+	// EquivalentRank 按一分一段表换算的等效高考位次，无数据时为0
+	EquivalentRank int32 `json:"equivalent_rank"`
+	// ScoreChange 与上一次考试相比的分数变化
+	ScoreChange int32 `json:"score_change"`
+	// EquivalentRankChange 与上一次考试相比的等效位次变化，负数表示进步
+	EquivalentRankChange int32 `json:"equivalent_rank_change"`
+}
+
+// EquivalentRank converts the snapshot score to a rank in the official score-rank table
+func (s *ProfileSnapshot) EquivalentRank(ctx context.Context, province string) (int32, error) {
+	rank, err := QueryRankByScore(ctx, ConvertProvinceNameToPinyin(province),
+		ScoreRankCategory(strings.Join(s.Subjects, ",")), ScoreRankYear, int(s.Score))
+	if err != nil {
+		return 0, err
+	}
+	return int32(rank), nil
+}
+
+// ==================== Database Operations ====================
+
+// CreateProfileSnapshot records a new snapshot for a profile
+func CreateProfileSnapshot(ctx context.Context, profile *UserProfile, request *ProfileSnapshotRequest) (*ProfileSnapshot, error) {
+	snapshot := &ProfileSnapshot{
+		ProfileID: profile.ID,
+		Label:     strings.TrimSpace(request.Label),
+		Score:     request.Score,
+		Rank:      request.Rank,
+		Subjects:  request.Subjects,
+	}
+	if len(snapshot.Subjects) == 0 {
+		snapshot.Subjects = profile.Subjects
+	}
+	if _, err := ParseSubjects(strings.Join(snapshot.Subjects, ",")); err != nil {
+		return nil, &ProfileValidationError{Field: "subjects", Msg: err.Error()}
+	}
+	if request.ExamDate != "" {
+		examDate, err := time.ParseInLocation("2006-01-02", request.ExamDate, time.Local)
+		if err != nil {
+			return nil, &ProfileValidationError{Field: "exam_date", Msg: "日期格式应为 YYYY-MM-DD"}
+		}
+		snapshot.ExamDate = &examDate
+	}
+
+	db := database.GetDB().WithContext(ctx)
+	if result := db.Create(snapshot); result.Error != nil {
+		return nil, result.Error
+	}
+	return snapshot, nil
+}
+
+// GetProfileSnapshot retrieves one snapshot of a profile
+func GetProfileSnapshot(ctx context.Context, profileID string, snapshotID uint) (*ProfileSnapshot, error) {
+	var snapshot ProfileSnapshot
+	db := database.GetDB().WithContext(ctx)
+	if result := db.First(&snapshot, "id = ? AND profile_id = ?", snapshotID, profileID); result.Error != nil {
+		return nil, result.Error
+	}
+	return &snapshot, nil
+}
+
+// DeleteProfileSnapshot deletes one snapshot of a profile
+func DeleteProfileSnapshot(ctx context.Context, profileID string, snapshotID uint) error {
+	db := database.GetDB().WithContext(ctx)
+	return db.Where("id = ? AND profile_id = ?", snapshotID, profileID).Delete(&ProfileSnapshot{}).Error
+}
+
+// GetProfileTrend lists a profile's snapshots in exam order with rank-equivalent conversion
+func GetProfileTrend(ctx context.Context, profile *UserProfile) ([]ProfileTrendItem, error) {
+	var snapshots []ProfileSnapshot
+	db := database.GetDB().WithContext(ctx)
+	result := db.Where("profile_id = ?", profile.ID).
+		Order("CASE WHEN exam_date IS NULL THEN 1 ELSE 0 END, exam_date ASC, created_at ASC").
+		Find(&snapshots)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	trend := make([]ProfileTrendItem, 0, len(snapshots))
+	for i, snapshot := range snapshots {
+		item := ProfileTrendItem{ProfileSnapshot: snapshot}
+		// 缺少一分一段数据时等效位次保持为0
+		item.EquivalentRank, _ = snapshot.EquivalentRank(ctx, profile.Province)
+
+		if i > 0 {
+			prev := trend[i-1]
+			item.ScoreChange = item.Score - prev.Score
+			if item.EquivalentRank > 0 && prev.EquivalentRank > 0 {
+				item.EquivalentRankChange = item.EquivalentRank - prev.EquivalentRank
+			}
+		}
+		trend = append(trend, item)
+	}
+	return trend, nil
+}
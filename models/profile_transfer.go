@@ -0,0 +1,383 @@
+package models
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/i18n"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 导入文件的列名（支持中英文表头），映射到统一字段名
+var profileImportColumns = map[string]string{
+	"name":                "username",
+	"username":            "username",
+	"姓名":                  "username",
+	"province":            "province",
+	"省份":                  "province",
+	"gender":              "gender",
+	"性别":                  "gender",
+	"subjects":            "subjects",
+	"选科":                  "subjects",
+	"科目":                  "subjects",
+	"score":               "score",
+	"分数":                  "score",
+	"rank":                "rank",
+	"位次":                  "rank",
+	"priority_strategy":   "priority_strategy",
+	"填报策略":                "priority_strategy",
+	"major_preference":    "major_preference",
+	"专业偏好":                "major_preference",
+	"region_preference":   "region_preference",
+	"地区偏好":                "region_preference",
+	"target_universities": "target_universities",
+	"目标院校":                "target_universities",
+	"career_interest":     "career_interest",
+	"职业兴趣":                "career_interest",
+	"graduation_plan":     "graduation_plan",
+	"毕业规划":                "graduation_plan",
+	"tuition_preference":  "tuition_preference",
+	"学费偏好":                "tuition_preference",
+	"other":               "other",
+	"备注":                  "other",
+}
+
+// profileListSeparator 单元格内多个值的分隔符
+var profileListSeparator = regexp.MustCompile(`[,，;；、/|]`)
+
+// profileExportHeader 导出 CSV 的表头，与导入字段名一致以便回导
+var profileExportHeader = []string{
+	"id", "username", "gender", "province", "subjects", "score", "rank",
+	"priority_strategy", "major_preference", "region_preference", "target_universities",
+	"career_interest", "graduation_plan", "tuition_preference", "other",
+	"completion", "recommendations",
+}
+
+// ProfileImportError is a row-level import failure; Line and Column are 1-based positions in the file,
+// Column is set when the failure is caused by a single cell
+type ProfileImportError struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+	Field  string `json:"field,omitempty"`
+	Msg    string `json:"msg"`
+}
+
+// ProfileImportCreated is a successfully imported (or, in dry-run, validated) row
+type ProfileImportCreated struct {
+	Line      int      `json:"line"`
+	ProfileID string   `json:"profile_id,omitempty"`
+	Username  string   `json:"username"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// ProfileImportResult summarises a bulk import
+type ProfileImportResult struct {
+	DryRun  bool                   `json:"dry_run"`
+	Total   int                    `json:"total"`
+	Created []ProfileImportCreated `json:"created"`
+	Errors  []ProfileImportError   `json:"errors"`
+}
+
+// ProfileExport is a profile together with its saved application form
+type ProfileExport struct {
+	UserProfile
+	Completion      int32                 `json:"completion"`
+	Recommendations []SavedRecommendation `json:"recommendations"`
+}
+
+// ReadProfileImport reads all rows of a CSV or XLSX (first sheet) import file
+func ReadProfileImport(r io.Reader, format string) ([][]string, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, errcode.Newf(errcode.InvalidImportFile, "解析CSV失败: %v", err)
+		}
+		// 去除 Excel 导出 CSV 时带的 UTF-8 BOM
+		if len(records) > 0 && len(records[0]) > 0 {
+			records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+		}
+		return records, nil
+	case "xlsx":
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, errcode.Newf(errcode.InvalidImportFile, "解析XLSX失败: %v", err)
+		}
+		defer file.Close()
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errcode.New(errcode.InvalidImportFile, "XLSX文件没有工作表")
+		}
+		rows, err := file.GetRows(sheets[0])
+		if err != nil {
+			return nil, errcode.Newf(errcode.InvalidImportFile, "解析XLSX失败: %v", err)
+		}
+		return rows, nil
+	default:
+		return nil, errcode.New(errcode.UnsupportedFormat, format)
+	}
+}
+
+// ImportUserProfiles validates every row with the profile validation rules and creates
+// profiles on behalf of userID. Invalid rows are reported without aborting the import;
+// with dryRun no profile is written.
+func ImportUserProfiles(ctx context.Context, records [][]string, userID string, dryRun bool) (*ProfileImportResult, error) {
+	if len(records) == 0 {
+		return nil, errcode.New(errcode.InvalidImportFile, "导入文件为空")
+	}
+
+	// 解析表头
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		if field, ok := profileImportColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	for _, required := range []string{"username", "province", "subjects"} {
+		if _, ok := columns[required]; !ok {
+			return nil, errcode.Newf(errcode.InvalidImportFile, "缺少必需列: %s", required)
+		}
+	}
+
+	result := &ProfileImportResult{
+		DryRun:  dryRun,
+		Created: []ProfileImportCreated{},
+		Errors:  []ProfileImportError{},
+	}
+
+	lang := i18n.FromContext(ctx)
+	for i, record := range records[1:] {
+		line := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+		result.Total++
+
+		request, importErr := profileRequestFromRecord(record, columns, lang)
+		if importErr != nil {
+			importErr.Line = line
+			result.Errors = append(result.Errors, *importErr)
+			continue
+		}
+
+		var (
+			profileID string
+			warnings  []string
+			err       error
+		)
+		if dryRun {
+			warnings, err = ValidateProfileRequest(ctx, request)
+		} else {
+			var profile *UserProfile
+			if profile, err = CreateUserProfile(ctx, request, userID); err == nil {
+				profileID, warnings = profile.ID, profile.Warnings
+			}
+		}
+		if err != nil {
+			rowErr := ProfileImportError{Line: line, Msg: errcode.From(err).Message(lang)}
+			var validationErr *ProfileValidationError
+			if errors.As(err, &validationErr) {
+				rowErr.Field, rowErr.Msg = validationErr.Field, i18n.T(lang, validationErr.Msg)
+			}
+			result.Errors = append(result.Errors, rowErr)
+			continue
+		}
+
+		result.Created = append(result.Created, ProfileImportCreated{
+			Line:      line,
+			ProfileID: profileID,
+			Username:  request.Username,
+			Warnings:  warnings,
+		})
+	}
+
+	return result, nil
+}
+
+// profileRequestFromRecord 将一行导入数据转换为档案请求
+func profileRequestFromRecord(record []string, columns map[string]int, lang i18n.Lang) (*UserProfileRequest, *ProfileImportError) {
+	cell := func(field string) string {
+		if i, ok := columns[field]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	optional := func(field string) *string {
+		if value := cell(field); value != "" {
+			return &value
+		}
+		return nil
+	}
+	number := func(field string) (int32, *ProfileImportError) {
+		value := cell(field)
+		n, err := parseProfileNumber(value)
+		if err != nil {
+			msg := i18n.T(lang, "不是有效的整数: %s", value)
+			if errors.Is(err, strconv.ErrRange) {
+				msg = i18n.T(lang, "超出取值范围: %s", value)
+			}
+			return 0, &ProfileImportError{Column: columns[field] + 1, Field: field, Msg: msg}
+		}
+		return n, nil
+	}
+
+	score, importErr := number("score")
+	if importErr != nil {
+		return nil, importErr
+	}
+	rank, importErr := number("rank")
+	if importErr != nil {
+		return nil, importErr
+	}
+
+	preference := &Preference{
+		CareerInterest:     splitProfileList(cell("career_interest")),
+		GraduationPlan:     splitProfileList(cell("graduation_plan")),
+		MajorPreference:    splitProfileList(cell("major_preference")),
+		Other:              optional("other"),
+		PriorityStrategy:   cell("priority_strategy"),
+		RegionPreference:   splitProfileList(cell("region_preference")),
+		TargetUniversities: splitProfileList(cell("target_universities")),
+		TuitionPreference:  optional("tuition_preference"),
+	}
+	if preference.PriorityStrategy == "" {
+		preference.PriorityStrategy = "school"
+	}
+
+	return &UserProfileRequest{
+		Username:   cell("username"),
+		Gender:     optional("gender"),
+		Province:   cell("province"),
+		Subjects:   splitProfileList(cell("subjects")),
+		Score:      score,
+		Rank:       rank,
+		Preference: preference,
+	}, nil
+}
+
+// parseProfileNumber 解析导入文件中的整数单元格，空单元格为 0
+// 小数、非数字和超出 int32 范围的值返回错误，不做截断
+func parseProfileNumber(value string) (int32, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return int32(n), nil
+}
+
+// splitProfileList 拆分单元格中的多个值
+func splitProfileList(value string) []string {
+	var items []string
+	for _, item := range profileListSeparator.Split(value, -1) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// isBlankRecord 判断是否为空行
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildProfileExports loads the saved application form of each profile for export
+func BuildProfileExports(ctx context.Context, profiles []UserProfile) ([]ProfileExport, error) {
+	profileIDs := make([]string, len(profiles))
+	for i, profile := range profiles {
+		profileIDs[i] = profile.ID
+	}
+	saved, err := ListSavedRecommendationsByProfiles(ctx, profileIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make([]ProfileExport, 0, len(profiles))
+	for _, profile := range profiles {
+		recommendations := saved[profile.ID]
+		if recommendations == nil {
+			recommendations = []SavedRecommendation{}
+		}
+		exports = append(exports, ProfileExport{
+			UserProfile:     profile,
+			Completion:      profile.Completion(),
+			Recommendations: recommendations,
+		})
+	}
+	return exports, nil
+}
+
+// WriteProfilesCSV writes exported profiles as CSV, one profile per row.
+// Saved recommendations are flattened into a single "recommendations" column.
+func WriteProfilesCSV(w io.Writer, exports []ProfileExport) error {
+	// 写入 BOM 以便 Excel 正确识别 UTF-8
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(profileExportHeader); err != nil {
+		return err
+	}
+
+	deref := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+
+	for _, export := range exports {
+		var recommendations []string
+		for _, rec := range export.Recommendations {
+			recommendations = append(recommendations, fmt.Sprintf("%d.%s(%s)-%s",
+				rec.SortOrder, rec.UniversityName, rec.SchoolCode, rec.GroupCode))
+		}
+
+		pref := export.Preference
+		row := []string{
+			export.ID,
+			export.Username,
+			deref(export.Gender),
+			export.Province,
+			strings.Join(export.Subjects, ","),
+			strconv.Itoa(int(export.Score)),
+			strconv.Itoa(int(export.Rank)),
+			pref.PriorityStrategy,
+			strings.Join(pref.MajorPreference, ","),
+			strings.Join(pref.RegionPreference, ","),
+			strings.Join(pref.TargetUniversities, ","),
+			strings.Join(pref.CareerInterest, ","),
+			strings.Join(pref.GraduationPlan, ","),
+			deref(pref.TuitionPreference),
+			deref(pref.Other),
+			strconv.Itoa(int(export.Completion)),
+			strings.Join(recommendations, "; "),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
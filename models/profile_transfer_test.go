@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"gaokao-data-analysis/i18n"
+)
+
+func TestParseProfileNumber(t *testing.T) {
+	tests := []struct {
+		value     string
+		want      int32
+		wantErr   bool
+		wantRange bool
+	}{
+		{value: "", want: 0},
+		{value: "0", want: 0},
+		{value: "615", want: 615},
+		{value: "+12", want: 12},
+		{value: "-3", want: -3},
+		{value: "2147483647", want: 2147483647},
+		{value: "615.5", wantErr: true},
+		{value: "1e3", wantErr: true},
+		{value: "六百", wantErr: true},
+		{value: "2147483648", wantErr: true, wantRange: true},
+		{value: "-2147483649", wantErr: true, wantRange: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseProfileNumber(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseProfileNumber(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if errors.Is(err, strconv.ErrRange) != tt.wantRange {
+				t.Errorf("parseProfileNumber(%q) error = %v, want range error %v", tt.value, err, tt.wantRange)
+			}
+			if got != tt.want {
+				t.Errorf("parseProfileNumber(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileRequestFromRecordNumbers(t *testing.T) {
+	columns := map[string]int{"username": 0, "province": 1, "subjects": 2, "score": 3, "rank": 4}
+
+	tests := []struct {
+		name      string
+		record    []string
+		lang      i18n.Lang
+		wantScore int32
+		wantRank  int32
+		wantErr   *ProfileImportError
+	}{
+		{
+			name:      "分数和位次",
+			record:    []string{"张三", "湖北", "物理,化学,生物", " 615 ", "8000"},
+			wantScore: 615,
+			wantRank:  8000,
+		},
+		{
+			name:      "空单元格为0",
+			record:    []string{"张三", "湖北", "物理,化学,生物", "615", ""},
+			wantScore: 615,
+		},
+		{
+			name:      "缺少末尾单元格为0",
+			record:    []string{"张三", "湖北", "物理,化学,生物", "615"},
+			wantScore: 615,
+		},
+		{
+			name:    "分数不是整数",
+			record:  []string{"张三", "湖北", "物理,化学,生物", "615.5", "8000"},
+			wantErr: &ProfileImportError{Column: 4, Field: "score", Msg: "不是有效的整数: 615.5"},
+		},
+		{
+			name:    "位次超出取值范围",
+			record:  []string{"张三", "湖北", "物理,化学,生物", "615", "99999999999"},
+			wantErr: &ProfileImportError{Column: 5, Field: "rank", Msg: "超出取值范围: 99999999999"},
+		},
+		{
+			name:    "英文错误消息",
+			record:  []string{"张三", "湖北", "物理,化学,生物", "abc", "8000"},
+			lang:    i18n.English,
+			wantErr: &ProfileImportError{Column: 4, Field: "score", Msg: "not a valid integer: abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang := tt.lang
+			if lang == "" {
+				lang = i18n.Default
+			}
+			req, importErr := profileRequestFromRecord(tt.record, columns, lang)
+
+			if tt.wantErr != nil {
+				if importErr == nil || *importErr != *tt.wantErr {
+					t.Fatalf("profileRequestFromRecord() error = %+v, want %+v", importErr, tt.wantErr)
+				}
+				return
+			}
+			if importErr != nil {
+				t.Fatalf("profileRequestFromRecord() unexpected error: %+v", importErr)
+			}
+			if req.Score != tt.wantScore || req.Rank != tt.wantRank {
+				t.Errorf("profileRequestFromRecord() score, rank = %d, %d, want %d, %d", req.Score, req.Rank, tt.wantScore, tt.wantRank)
+			}
+		})
+	}
+}
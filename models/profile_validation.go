@@ -0,0 +1,107 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/i18n"
+)
+
+// ProfileValidationError 档案校验失败，属于客户端输入错误
+type ProfileValidationError struct {
+	Field string
+	Msg   string
+}
+
+func (e *ProfileValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Msg)
+}
+
+// ErrorCode 实现 errcode.Coder
+func (e *ProfileValidationError) ErrorCode() errcode.Code {
+	return errcode.InvalidProfile
+}
+
+// Localize 实现 i18n.Localizable，字段名保持原样，只翻译校验消息
+func (e *ProfileValidationError) Localize(lang i18n.Lang) string {
+	return e.Field + ": " + i18n.T(lang, e.Msg)
+}
+
+// scoreTolerance 分数与位次换算结果允许的最大分差
+var scoreTolerance = 5
+
+// SetScoreTolerance 设置分数与位次换算结果允许的最大分差，在启动时调用
+func SetScoreTolerance(tolerance int) {
+	scoreTolerance = tolerance
+}
+
+// ValidateProfileRequest 校验档案请求，并根据一分一段表补全缺失的分数或位次
+// 返回的 warnings 表示不影响保存但需要提示用户的问题（例如分数与位次不一致）
+func ValidateProfileRequest(ctx context.Context, req *UserProfileRequest) ([]string, error) {
+	if strings.TrimSpace(req.Username) == "" {
+		return nil, &ProfileValidationError{Field: "username", Msg: "不能为空"}
+	}
+	if strings.TrimSpace(req.Province) == "" {
+		return nil, &ProfileValidationError{Field: "province", Msg: "不能为空"}
+	}
+	if len(req.Subjects) == 0 {
+		return nil, &ProfileValidationError{Field: "subjects", Msg: "不能为空"}
+	}
+
+	// 验证科目组合
+	subjects := strings.Join(req.Subjects, ",")
+	if _, err := ParseSubjects(subjects); err != nil {
+		return nil, &ProfileValidationError{Field: "subjects", Msg: err.Error()}
+	}
+
+	if req.Score < 0 {
+		return nil, &ProfileValidationError{Field: "score", Msg: "分数不能为负数"}
+	}
+	if req.Rank < 0 {
+		return nil, &ProfileValidationError{Field: "rank", Msg: "位次不能为负数"}
+	}
+	if req.Score == 0 && req.Rank == 0 {
+		return nil, &ProfileValidationError{Field: "score", Msg: "分数和位次至少需要提供一项"}
+	}
+
+	province := ConvertProvinceNameToPinyin(req.Province)
+	category := ScoreRankCategory(subjects)
+
+	var warnings []string
+
+	switch {
+	case req.Rank == 0:
+		// 根据分数补全位次
+		rank, err := QueryRankByScore(ctx, province, category, ScoreRankYear, int(req.Score))
+		if err != nil {
+			return nil, &ProfileValidationError{Field: "rank", Msg: "无法根据分数推算位次，请填写位次"}
+		}
+		req.Rank = int32(rank)
+	case req.Score == 0:
+		// 根据位次补全分数
+		score, err := QueryScoreByRank(ctx, province, category, ScoreRankYear, int(req.Rank))
+		if err != nil {
+			return nil, &ProfileValidationError{Field: "score", Msg: "无法根据位次推算分数，请填写分数"}
+		}
+		req.Score = int32(score)
+	default:
+		// 分数和位次都提供时，校验两者是否与一分一段表一致
+		expected, err := QueryScoreByRank(ctx, province, category, ScoreRankYear, int(req.Rank))
+		if err != nil {
+			warnings = append(warnings, i18n.T(i18n.FromContext(ctx), "缺少一分一段数据，未校验分数与位次是否一致"))
+			break
+		}
+		diff := int(req.Score) - expected
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > scoreTolerance {
+			warnings = append(warnings, i18n.T(i18n.FromContext(ctx), "位次 %d 对应分数约为 %d，与填写的分数 %d 相差 %d 分，请核对",
+				req.Rank, expected, req.Score, diff))
+		}
+	}
+
+	return warnings, nil
+}
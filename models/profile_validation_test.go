@@ -0,0 +1,153 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testScoreRankProvince 测试使用的省份，没有对应的一分一段文件，数据只来自缓存
+const testScoreRankProvince = "测试省"
+
+// seedScoreRankCache 写入测试省份的物理类一分一段数据
+func seedScoreRankCache(t *testing.T) {
+	t.Helper()
+	key := getScoreRankCacheKey(ConvertProvinceNameToPinyin(testScoreRankProvince), "physics", ScoreRankYear)
+	data := processScoreRankData(&ScoreRankData{Data: []ScoreRankItem{
+		{Score: "600", Num: 100, Accumulate: 5000},
+		{Score: "590", Num: 300, Accumulate: 8000},
+		{Score: "580", Num: 400, Accumulate: 12000},
+	}})
+
+	scoreRankMutex.Lock()
+	processedScoreRankCache[key] = data
+	scoreRankMutex.Unlock()
+	t.Cleanup(func() {
+		scoreRankMutex.Lock()
+		delete(processedScoreRankCache, key)
+		scoreRankMutex.Unlock()
+	})
+}
+
+func TestValidateProfileRequest(t *testing.T) {
+	seedScoreRankCache(t)
+
+	physics := []string{"物理", "化学", "生物"}
+	tests := []struct {
+		name         string
+		req          UserProfileRequest
+		wantErr      *ProfileValidationError
+		wantScore    int32
+		wantRank     int32
+		wantWarnings []string
+	}{
+		{
+			name:    "用户名为空",
+			req:     UserProfileRequest{Username: "  ", Province: testScoreRankProvince, Subjects: physics, Score: 600},
+			wantErr: &ProfileValidationError{Field: "username", Msg: "不能为空"},
+		},
+		{
+			name:    "省份为空",
+			req:     UserProfileRequest{Username: "张三", Subjects: physics, Score: 600},
+			wantErr: &ProfileValidationError{Field: "province", Msg: "不能为空"},
+		},
+		{
+			name:    "科目为空",
+			req:     UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Score: 600},
+			wantErr: &ProfileValidationError{Field: "subjects", Msg: "不能为空"},
+		},
+		{
+			name:    "科目组合不含物理或历史",
+			req:     UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: []string{"化学", "生物", "政治"}, Score: 600},
+			wantErr: &ProfileValidationError{Field: "subjects", Msg: "科目组合必须包含物理或历史"},
+		},
+		{
+			name:    "分数为负数",
+			req:     UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: physics, Score: -1},
+			wantErr: &ProfileValidationError{Field: "score", Msg: "分数不能为负数"},
+		},
+		{
+			name:    "位次为负数",
+			req:     UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: physics, Rank: -1},
+			wantErr: &ProfileValidationError{Field: "rank", Msg: "位次不能为负数"},
+		},
+		{
+			name:    "分数和位次都未填写",
+			req:     UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: physics},
+			wantErr: &ProfileValidationError{Field: "score", Msg: "分数和位次至少需要提供一项"},
+		},
+		{
+			name:      "根据分数补全位次",
+			req:       UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: physics, Score: 600},
+			wantScore: 600,
+			wantRank:  5000,
+		},
+		{
+			name:      "根据位次补全分数",
+			req:       UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: physics, Rank: 8000},
+			wantScore: 590,
+			wantRank:  8000,
+		},
+		{
+			name:      "分数与位次一致",
+			req:       UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: physics, Score: 600, Rank: 5000},
+			wantScore: 600,
+			wantRank:  5000,
+		},
+		{
+			name:      "分差在允许范围内",
+			req:       UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: physics, Score: 594, Rank: 8000},
+			wantScore: 594,
+			wantRank:  8000,
+		},
+		{
+			name:         "分差超出允许范围时提示核对",
+			req:          UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: physics, Score: 610, Rank: 8000},
+			wantScore:    610,
+			wantRank:     8000,
+			wantWarnings: []string{"位次 8000 对应分数约为 590，与填写的分数 610 相差 20 分，请核对"},
+		},
+		{
+			name:         "缺少一分一段数据时只提示未校验",
+			req:          UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: []string{"历史", "政治", "地理"}, Score: 600, Rank: 5000},
+			wantScore:    600,
+			wantRank:     5000,
+			wantWarnings: []string{"缺少一分一段数据，未校验分数与位次是否一致"},
+		},
+		{
+			name:    "缺少一分一段数据时无法补全位次",
+			req:     UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: []string{"历史", "政治", "地理"}, Score: 600},
+			wantErr: &ProfileValidationError{Field: "rank", Msg: "无法根据分数推算位次，请填写位次"},
+		},
+		{
+			name:    "缺少一分一段数据时无法补全分数",
+			req:     UserProfileRequest{Username: "张三", Province: testScoreRankProvince, Subjects: []string{"历史", "政治", "地理"}, Rank: 5000},
+			wantErr: &ProfileValidationError{Field: "score", Msg: "无法根据位次推算分数，请填写分数"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			warnings, err := ValidateProfileRequest(context.Background(), &req)
+
+			if tt.wantErr != nil {
+				var validationErr *ProfileValidationError
+				if !errors.As(err, &validationErr) || *validationErr != *tt.wantErr {
+					t.Fatalf("ValidateProfileRequest() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateProfileRequest() unexpected error: %v", err)
+			}
+			if req.Score != tt.wantScore || req.Rank != tt.wantRank {
+				t.Errorf("ValidateProfileRequest() score, rank = %d, %d, want %d, %d", req.Score, req.Rank, tt.wantScore, tt.wantRank)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("ValidateProfileRequest() warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// 查询结果按列名映射到结构体字段，字段通过 db 标签声明对应的列名：
+//   - 匿名嵌入且没有 db 标签的结构体会展开其字段
+//   - []string 字段可接收 ClickHouse 的 Array(String)，以及关系型数据库中的 JSON 数组或逗号分隔字符串
+//   - 指针和 sql.Null* 字段接收可空列，其它类型的转换与 rows.Scan 一致
+
+// fieldIndexCache 缓存结构体类型的列名与字段下标对应关系
+var fieldIndexCache sync.Map // reflect.Type -> map[string][]int
+
+var stringSliceType = reflect.TypeFor[[]string]()
+
+// fieldIndexes 返回结构体类型中 db 标签到字段下标的映射
+func fieldIndexes(t reflect.Type) map[string][]int {
+	if cached, ok := fieldIndexCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+
+	indexes := make(map[string][]int)
+	collectFieldIndexes(t, nil, indexes)
+	fieldIndexCache.Store(t, indexes)
+	return indexes
+}
+
+func collectFieldIndexes(t reflect.Type, parent []int, indexes map[string][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int(nil), parent...), i)
+		tag := field.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && tag == "" {
+			collectFieldIndexes(field.Type, index, indexes)
+			continue
+		}
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		indexes[tag] = index
+	}
+}
+
+// scanRows 将查询结果的每一行按列名映射为 T，查询结果中的列必须都有对应字段
+func scanRows[T any](rows *sql.Rows) ([]T, error) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("scanRows: %s is not a struct", t)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	fields := fieldIndexes(t)
+	columnFields := make([][]int, len(columns))
+	for i, column := range columns {
+		index, ok := fields[column]
+		if !ok {
+			return nil, fmt.Errorf("scanRows: column %q has no matching field in %s", column, t)
+		}
+		columnFields[i] = index
+	}
+
+	var result []T
+	dest := make([]interface{}, len(columns))
+	for rows.Next() {
+		var row T
+		value := reflect.ValueOf(&row).Elem()
+		for i, index := range columnFields {
+			field := value.FieldByIndex(index)
+			if field.Type() == stringSliceType {
+				dest[i] = &stringArray{dest: field.Addr().Interface().(*[]string)}
+			} else {
+				dest[i] = field.Addr().Interface()
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", t.Name(), err)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// stringArray 将 Array(String)、JSON 数组或逗号分隔字符串扫描为 []string
+type stringArray struct {
+	dest *[]string
+}
+
+// Scan 实现 sql.Scanner 接口
+func (a *stringArray) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a.dest = nil
+	case []string:
+		*a.dest = v
+	case []byte:
+		return a.parse(string(v))
+	case string:
+		return a.parse(v)
+	default:
+		return fmt.Errorf("cannot scan %T into []string", src)
+	}
+	return nil
+}
+
+func (a *stringArray) parse(value string) error {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		*a.dest = []string{}
+	case strings.HasPrefix(value, "["):
+		return json.Unmarshal([]byte(value), a.dest)
+	default:
+		*a.dest = strings.Split(value, ",")
+	}
+	return nil
+}
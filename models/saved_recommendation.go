@@ -0,0 +1,105 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gaokao-data-analysis/database"
+
+	"gorm.io/gorm"
+)
+
+// SavedRecommendation is one entry of a profile's saved application form (志愿表)
+type SavedRecommendation struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	ProfileID      string     `gorm:"type:varchar(36);not null;index" json:"profile_id"`
+	SortOrder      int32      `gorm:"not null" json:"sort_order"`
+	SchoolCode     string     `gorm:"type:varchar(20);not null" json:"school_code"`
+	UniversityName string     `gorm:"type:varchar(100)" json:"university_name"`
+	GroupCode      string     `gorm:"type:varchar(20);not null" json:"group_code"`
+	MajorCodes     StringList `gorm:"type:json" json:"major_codes"`
+	Strategy       int32      `json:"strategy"`
+	Probability    int32      `json:"probability"`
+	Note           string     `gorm:"type:varchar(255)" json:"note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// SaveRecommendationsRequest represents the API request to replace a profile's saved application form
+type SaveRecommendationsRequest struct {
+	Items []SavedRecommendationItem `json:"items" binding:"dive"`
+}
+
+// SavedRecommendationItem is one entry in SaveRecommendationsRequest
+type SavedRecommendationItem struct {
+	SchoolCode     string   `json:"school_code" binding:"required"`
+	UniversityName string   `json:"university_name,omitempty"`
+	GroupCode      string   `json:"group_code" binding:"required"`
+	MajorCodes     []string `json:"major_codes,omitempty"`
+	Strategy       int32    `json:"strategy"`
+	Probability    int32    `json:"probability"`
+	Note           string   `json:"note,omitempty"`
+}
+
+// ==================== Database Operations ====================
+
+// ReplaceSavedRecommendations replaces a profile's saved application form, keeping the request order
+func ReplaceSavedRecommendations(ctx context.Context, profileID string, items []SavedRecommendationItem) ([]SavedRecommendation, error) {
+	recommendations := make([]SavedRecommendation, 0, len(items))
+	for i, item := range items {
+		recommendations = append(recommendations, SavedRecommendation{
+			ProfileID:      profileID,
+			SortOrder:      int32(i + 1),
+			SchoolCode:     item.SchoolCode,
+			UniversityName: item.UniversityName,
+			GroupCode:      item.GroupCode,
+			MajorCodes:     item.MajorCodes,
+			Strategy:       item.Strategy,
+			Probability:    item.Probability,
+			Note:           item.Note,
+		})
+	}
+
+	db := database.GetDB().WithContext(ctx)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("profile_id = ?", profileID).Delete(&SavedRecommendation{}).Error; err != nil {
+			return err
+		}
+		if len(recommendations) == 0 {
+			return nil
+		}
+		return tx.Create(&recommendations).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recommendations, nil
+}
+
+// ListSavedRecommendations lists a profile's saved application form in order
+func ListSavedRecommendations(ctx context.Context, profileID string) ([]SavedRecommendation, error) {
+	var recommendations []SavedRecommendation
+	db := database.GetDB().WithContext(ctx)
+	if result := db.Where("profile_id = ?", profileID).Order("sort_order ASC").Find(&recommendations); result.Error != nil {
+		return nil, result.Error
+	}
+	return recommendations, nil
+}
+
+// ListSavedRecommendationsByProfiles lists the saved application forms of several profiles in one query,
+// keyed by profile ID; profiles without a saved form are absent from the map
+func ListSavedRecommendationsByProfiles(ctx context.Context, profileIDs []string) (map[string][]SavedRecommendation, error) {
+	result := make(map[string][]SavedRecommendation, len(profileIDs))
+	if len(profileIDs) == 0 {
+		return result, nil
+	}
+
+	var recommendations []SavedRecommendation
+	db := database.GetDB().WithContext(ctx)
+	if err := db.Where("profile_id IN ?", profileIDs).Order("profile_id, sort_order ASC").Find(&recommendations).Error; err != nil {
+		return nil, err
+	}
+	for _, rec := range recommendations {
+		result[rec.ProfileID] = append(result[rec.ProfileID], rec)
+	}
+	return result, nil
+}
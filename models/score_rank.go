@@ -0,0 +1,376 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/metrics"
+	"gaokao-data-analysis/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ScoreRankItem represents a single score-rank mapping entry
+type ScoreRankItem struct {
+	Score      string `json:"score"`      // 分数，可能是单个分数或分数区间
+	Num        int    `json:"num"`        // 该分数段人数
+	Accumulate int    `json:"accumulate"` // 累计人数（位次）
+}
+
+// ScoreRankData represents the complete score-rank data structure
+type ScoreRankData struct {
+	Data []ScoreRankItem `json:"data"`
+}
+
+// ProcessedScoreRankData represents the processed and optimized score-rank data
+type ProcessedScoreRankData struct {
+	ScoreToRank  map[int]int // 分数到位次的映射
+	RankToScore  map[int]int // 位次到分数的映射
+	MinScore     int         // 最低分数
+	MaxScore     int         // 最高分数
+	MinRank      int         // 最好位次（最小值）
+	MaxRank      int         // 最差位次（最大值）
+	SortedScores []int       // 排序后的分数列表，用于二分查找
+	SortedRanks  []int       // 排序后的位次列表，用于二分查找
+}
+
+// ScoreRankYear 一分一段表默认使用的年份
+const ScoreRankYear = 2024
+
+var (
+	// processedScoreRankCache 缓存已处理的分数位次数据
+	processedScoreRankCache = make(map[string]*ProcessedScoreRankData)
+	// scoreRankMutex 保护缓存的读写锁
+	scoreRankMutex sync.RWMutex
+)
+
+// getScoreRankCacheKey 生成缓存键
+func getScoreRankCacheKey(province, category string, year int) string {
+	return fmt.Sprintf("%s_%s_%d", strings.ToLower(province), strings.ToLower(category), year)
+}
+
+// processScoreRankData 处理原始JSON数据，生成优化的查询结构
+func processScoreRankData(rawData *ScoreRankData) *ProcessedScoreRankData {
+	processed := &ProcessedScoreRankData{
+		ScoreToRank:  make(map[int]int),
+		RankToScore:  make(map[int]int),
+		SortedScores: make([]int, 0),
+		SortedRanks:  make([]int, 0),
+		MinScore:     999999,
+		MaxScore:     0,
+		MinRank:      999999,
+		MaxRank:      0,
+	}
+
+	// 处理每个数据项
+	for _, item := range rawData.Data {
+		// 处理单个分数
+		score, err := strconv.Atoi(item.Score)
+		if err != nil {
+			continue
+		}
+
+		rank := item.Accumulate
+		processed.ScoreToRank[score] = rank
+		processed.RankToScore[rank] = score
+
+		if score < processed.MinScore {
+			processed.MinScore = score
+		}
+		if score > processed.MaxScore {
+			processed.MaxScore = score
+		}
+		if rank < processed.MinRank {
+			processed.MinRank = rank
+		}
+		if rank > processed.MaxRank {
+			processed.MaxRank = rank
+		}
+	}
+
+	// 生成排序后的分数列表，用于快速查找
+	for score := range processed.ScoreToRank {
+		processed.SortedScores = append(processed.SortedScores, score)
+	}
+
+	// 生成排序后的位次列表，用于快速查找
+	for rank := range processed.RankToScore {
+		processed.SortedRanks = append(processed.SortedRanks, rank)
+	}
+
+	// 按分数从高到低排序
+	sort.Sort(sort.Reverse(sort.IntSlice(processed.SortedScores)))
+
+	// 按位次从小到大排序
+	sort.Ints(processed.SortedRanks)
+
+	return processed
+}
+
+// loadScoreRankData 加载并处理分数位次数据，支持缓存
+func loadScoreRankData(province, category string, year int) (*ProcessedScoreRankData, error) {
+	cacheKey := getScoreRankCacheKey(province, category, year)
+
+	// 先尝试从缓存读取
+	scoreRankMutex.RLock()
+	if cachedData, exists := processedScoreRankCache[cacheKey]; exists {
+		scoreRankMutex.RUnlock()
+		metrics.ScoreRankCache.WithLabelValues("hit").Inc()
+		return cachedData, nil
+	}
+	scoreRankMutex.RUnlock()
+	metrics.ScoreRankCache.WithLabelValues("miss").Inc()
+
+	// 缓存未命中，从文件加载
+	fileName := fmt.Sprintf("score_rank_%s_%d_%s.json", strings.ToLower(province), year, strings.ToLower(category))
+	filePath := filepath.Join("static", fileName)
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取文件 %s: %v", fileName, err)
+	}
+
+	var rawData ScoreRankData
+	if err := json.Unmarshal(data, &rawData); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %v", err)
+	}
+
+	// 处理原始数据
+	processedData := processScoreRankData(&rawData)
+
+	// 写入缓存
+	scoreRankMutex.Lock()
+	processedScoreRankCache[cacheKey] = processedData
+	scoreRankMutex.Unlock()
+
+	return processedData, nil
+}
+
+// findRankByScore 根据分数查找对应的位次（使用处理后的数据）
+func findRankByScore(processedData *ProcessedScoreRankData, targetScore int) int {
+	// 直接从映射中查找精确匹配
+	if rank, exists := processedData.ScoreToRank[targetScore]; exists {
+		return rank
+	}
+
+	// 如果目标分数超出范围，返回边界值
+	if targetScore > processedData.MaxScore {
+		return processedData.MinRank // 分数最高，位次最好（最小）
+	}
+	if targetScore < processedData.MinScore {
+		return processedData.MaxRank // 分数最低，位次最差（最大）
+	}
+
+	// 使用二分查找找到最接近的较低分数
+	// 因为分数越低，位次越高（数字越大）
+	left, right := 0, len(processedData.SortedScores)-1
+	bestScore := processedData.MinScore
+
+	for left <= right {
+		mid := (left + right) / 2
+		score := processedData.SortedScores[mid]
+
+		if score == targetScore {
+			return processedData.ScoreToRank[score]
+		} else if score > targetScore {
+			left = mid + 1
+		} else {
+			// score < targetScore，这是一个候选
+			bestScore = score
+			right = mid - 1
+		}
+	}
+
+	// 返回找到的最接近的较低分数对应的位次
+	if rank, exists := processedData.ScoreToRank[bestScore]; exists {
+		return rank
+	}
+
+	return processedData.MaxRank
+}
+
+// findScoreByRank 根据位次查找对应的分数（使用处理后的数据）
+func findScoreByRank(processedData *ProcessedScoreRankData, targetRank int) int {
+	// 直接从映射中查找精确匹配
+	if score, exists := processedData.RankToScore[targetRank]; exists {
+		return score
+	}
+
+	// 如果目标位次超出范围，返回边界值
+	if targetRank < processedData.MinRank {
+		return processedData.MaxScore // 位次最好，分数最高
+	}
+	if targetRank > processedData.MaxRank {
+		return processedData.MinScore // 位次最差，分数最低
+	}
+
+	// 使用二分查找找到最接近的较大位次（较差位次）
+	// 对于位次转分数：如果没有精确匹配，应该返回比目标位次稍差的位次对应的分数
+	// 这样更保守，不会高估学生的分数
+	left, right := 0, len(processedData.SortedRanks)-1
+	result := processedData.MinScore
+
+	for left <= right {
+		mid := (left + right) / 2
+		rank := processedData.SortedRanks[mid]
+
+		if rank == targetRank {
+			return processedData.RankToScore[rank]
+		} else if rank < targetRank {
+			left = mid + 1
+		} else {
+			// rank > targetRank，这个位次比目标位次差，对应的分数更低
+			if score, exists := processedData.RankToScore[rank]; exists {
+				result = score
+			}
+			right = mid - 1
+		}
+	}
+
+	return result
+}
+
+// QueryScoreByRank 根据省份、类别、年份和位次查询对应的分数
+func QueryScoreByRank(ctx context.Context, province, category string, year, rank int) (score int, err error) {
+	_, span := telemetry.Start(ctx, "score_rank.QueryScoreByRank",
+		attribute.String("province", province),
+		attribute.String("category", category),
+		attribute.Int("year", year),
+		attribute.Int("rank", rank),
+	)
+	defer telemetry.End(span, &err)
+
+	// 验证输入参数
+	if rank <= 0 {
+		return 0, errcode.New(errcode.InvalidRequest, "位次必须大于0")
+	}
+
+	// 验证类别参数
+	if category != "physics" && category != "history" {
+		return 0, errcode.New(errcode.InvalidRequest, "类别参数错误，只支持 physics 或 history")
+	}
+
+	// 加载分数位次数据
+	processedData, err := loadScoreRankData(province, category, year)
+	if err != nil {
+		return 0, errcode.Wrap(errcode.ScoreRankNotFound, err)
+	}
+
+	// 验证数据是否为空
+	if len(processedData.SortedRanks) == 0 {
+		return 0, errcode.New(errcode.ScoreRankNotFound, "")
+	}
+
+	// 查找对应分数
+	score = findScoreByRank(processedData, rank)
+	if score == 0 {
+		return 0, errcode.Newf(errcode.ScoreRankNotFound, "位次 %d", rank)
+	}
+
+	return score, nil
+}
+
+// QueryRankByScore 根据省份、类别、年份和分数查询对应的位次
+func QueryRankByScore(ctx context.Context, province, category string, year, score int) (rank int, err error) {
+	_, span := telemetry.Start(ctx, "score_rank.QueryRankByScore",
+		attribute.String("province", province),
+		attribute.String("category", category),
+		attribute.Int("year", year),
+		attribute.Int("score", score),
+	)
+	defer telemetry.End(span, &err)
+
+	// 验证输入参数
+	if score <= 0 {
+		return 0, errcode.New(errcode.InvalidRequest, "分数必须大于0")
+	}
+
+	// 验证类别参数
+	if category != "physics" && category != "history" {
+		return 0, errcode.New(errcode.InvalidRequest, "类别参数错误，只支持 physics 或 history")
+	}
+
+	// 加载分数位次数据
+	processedData, err := loadScoreRankData(province, category, year)
+	if err != nil {
+		return 0, errcode.Wrap(errcode.ScoreRankNotFound, err)
+	}
+
+	// 验证数据是否为空
+	if len(processedData.SortedScores) == 0 {
+		return 0, errcode.New(errcode.ScoreRankNotFound, "")
+	}
+
+	// 查找对应位次
+	rank = findRankByScore(processedData, score)
+	if rank == 0 {
+		return 0, errcode.Newf(errcode.ScoreRankNotFound, "分数 %d", score)
+	}
+
+	return rank, nil
+}
+
+// ConvertProvinceNameToPinyin 将省份中文名转换为拼音
+func ConvertProvinceNameToPinyin(provinceName string) string {
+	// 省份中文名到拼音的映射
+	provinceMap := map[string]string{
+		"湖北":  "hubei",
+		"湖南":  "hunan",
+		"河北":  "hebei",
+		"河南":  "henan",
+		"山东":  "shandong",
+		"山西":  "shanxi",
+		"陕西":  "shaanxi",
+		"四川":  "sichuan",
+		"江苏":  "jiangsu",
+		"江西":  "jiangxi",
+		"浙江":  "zhejiang",
+		"安徽":  "anhui",
+		"福建":  "fujian",
+		"广东":  "guangdong",
+		"广西":  "guangxi",
+		"海南":  "hainan",
+		"贵州":  "guizhou",
+		"云南":  "yunnan",
+		"西藏":  "xizang",
+		"青海":  "qinghai",
+		"甘肃":  "gansu",
+		"宁夏":  "ningxia",
+		"新疆":  "xinjiang",
+		"内蒙古": "neimenggu",
+		"辽宁":  "liaoning",
+		"吉林":  "jilin",
+		"黑龙江": "heilongjiang",
+		"北京":  "beijing",
+		"天津":  "tianjin",
+		"上海":  "shanghai",
+		"重庆":  "chongqing",
+	}
+
+	// 查找对应的拼音
+	if pinyin, exists := provinceMap[provinceName]; exists {
+		return pinyin
+	}
+
+	// 如果没找到，返回原字符串的小写形式
+	return strings.ToLower(provinceName)
+}
+
+// ScoreRankCategory 根据科目组合确定一分一段表类别（physics/history），无法确定时返回空字符串
+func ScoreRankCategory(subjects string) string {
+	if strings.Contains(subjects, "物理") {
+		return "physics"
+	}
+	if strings.Contains(subjects, "历史") {
+		return "history"
+	}
+	return ""
+}
@@ -2,7 +2,6 @@ package models
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -24,114 +23,78 @@ type VoluntaryUniversityItem struct {
 	UniversityName string `json:"university_name"`
 }
 
-// queryUniversityCount 查询符合条件的院校总数
-func queryUniversityCount(ctx context.Context, db *sql.DB, qb *QueryBuilder) (int64, error) {
-	countQuery := fmt.Sprintf(`SELECT count(DISTINCT school_name) 
-FROM %s
-WHERE 1=1
-`, TABLE)
-
-	// 复制查询条件到计数查询
-	countQB := NewQueryBuilder(countQuery)
-	countQB.conditions = append(countQB.conditions, qb.conditions...)
-	countQB.args = append(countQB.args, qb.args...)
-
-	finalCountQuery, countArgs := countQB.Build()
-
-	slog.Info("查询符合条件的院校总数", "query", finalCountQuery, "args", countArgs)
-
-	var total int64
-	err := db.QueryRowContext(ctx, finalCountQuery, countArgs...).Scan(&total)
-	if err != nil {
-		slog.Error("查询志愿总数失败", "error", err.Error())
-		return 0, err
-	}
-
-	return total, nil
-}
-
-// executeUniversityQuery 执行院校查询并返回结果
-func executeUniversityQuery(ctx context.Context, db *sql.DB, query string, args []interface{}, req *VoluntaryUniversityPriorityRequest) ([]VoluntaryUniversityItem, error) {
-	slog.Info("查询志愿院校分页", "query", query, "args", args)
-
-	rows, err := db.QueryContext(ctx, query, args...)
+// executeUniversityQuery 执行院校分页查询并返回结果
+func executeUniversityQuery(ctx context.Context, repo AdmissionRepository, filter *UniversityFilter, limit, offset int32, req *VoluntaryUniversityPriorityRequest) ([]VoluntaryUniversityItem, error) {
+	rows, err := repo.ListUniversityGroups(ctx, filter, limit, offset)
 	if err != nil {
-		slog.Error("查询志愿院校分页失败", "error", err.Error())
 		return nil, err
 	}
-	defer rows.Close()
 
-	// 处理结果
-	var resultItems []VoluntaryUniversityItem
+	// 处理结果，院校按查询结果的顺序排列
+	var schools []*VoluntaryUniversityItem
 	schoolMap := make(map[string]*VoluntaryUniversityItem)
 	var schoolGroups []SchoolGroupPair
 
-	for rows.Next() {
-		var recruitCode, universityName, province, groupCode string
-		var category, tags string
-		var majorCount int
-
-		if err := rows.Scan(&recruitCode, &universityName, &province, &category, &tags, &groupCode, &majorCount); err != nil {
-			slog.Error("扫描志愿院校结果失败", "error", err.Error())
-			continue
-		}
-
+	for _, row := range rows {
 		// 检查学校是否已经存在
-		_, exists := schoolMap[universityName]
-		if !exists {
+		if _, exists := schoolMap[row.RecruitCode]; !exists {
 			// 创建新的院校条目
 			newItem := &VoluntaryUniversityItem{
-				RecruitCode:    recruitCode,
-				UniversityName: universityName,
-				Province:       province,
-				Category:       strings.Split(category, ","),
-				Tags:           strings.Split(tags, ","),
+				RecruitCode:    row.RecruitCode,
+				UniversityName: row.UniversityName,
+				Province:       row.Province,
+				Category:       strings.Split(row.Category, ","),
+				Tags:           row.Tags,
 				MajorGroup:     []VoluntaryMajorGroup{},
 			}
-			schoolMap[universityName] = newItem
+			schoolMap[row.RecruitCode] = newItem
+			schools = append(schools, newItem)
 		}
 
 		// 收集所有学校代码和专业组代码对
 		schoolGroups = append(schoolGroups, SchoolGroupPair{
-			SchoolCode: recruitCode,
-			GroupCode:  groupCode,
+			SchoolCode: row.RecruitCode,
+			GroupCode:  row.GroupCode,
 		})
 	}
 
 	// 批量获取专业组信息
 	if len(schoolGroups) > 0 {
+		// 专业按院校查询使用的策略筛选，无效策略与分数范围一致按稳处理；没有符合的专业时保留全部专业
+		strategy := req.Strategy
+		if strategy < 0 || strategy > 2 {
+			strategy = 1
+		}
 		majorGroupReq := &VoluntaryMajorGroupRequest{
-			Province:  req.Province,
-			ProfileID: req.ProfileID,
-			Score:     req.Score,
-			Rank:      req.Rank,
-			Strategy:  req.Strategy,
-			Subjects:  req.Subjects,
+			Province:     req.Province,
+			ProfileID:    req.ProfileID,
+			SnapshotID:   req.SnapshotID,
+			UserID:       req.UserID,
+			Score:        req.Score,
+			Rank:         req.Rank,
+			Strategies:   []int32{strategy},
+			IncludeAll:   true,
+			Subjects:     req.Subjects,
+			HistoryYears: req.HistoryYears,
 		}
 
-		majorGroupsMap, err := GetMajorGroupsDetail(ctx, schoolGroups, majorGroupReq)
+		majorGroupsMap, err := GetMajorGroups(ctx, schoolGroups, majorGroupReq)
 		if err != nil {
-			slog.Error("批量获取专业组信息失败", "error", err.Error())
+			slog.ErrorContext(ctx, "批量获取专业组信息失败", "error", err.Error())
 			return nil, fmt.Errorf("批量获取专业组信息失败: %w", err)
 		}
 
 		// 将专业组信息分配给对应的院校
 		for _, sg := range schoolGroups {
-			groupKey := fmt.Sprintf("%s-%s", sg.SchoolCode, sg.GroupCode)
-			if majorGroup, exists := majorGroupsMap[groupKey]; exists {
-				// 找到对应的学校
-				for _, schoolItem := range schoolMap {
-					if schoolItem.RecruitCode == sg.SchoolCode {
-						schoolItem.MajorGroup = append(schoolItem.MajorGroup, *majorGroup)
-						break
-					}
-				}
+			if majorGroup, exists := majorGroupsMap[sg]; exists {
+				schoolMap[sg.SchoolCode].MajorGroup = append(schoolMap[sg.SchoolCode].MajorGroup, *majorGroup)
 			}
 		}
 	}
 
 	// 转换为结果切片
-	for _, item := range schoolMap {
+	var resultItems []VoluntaryUniversityItem
+	for _, item := range schools {
 		resultItems = append(resultItems, *item)
 	}
 
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gaokao-data-analysis/database"
+	"gaokao-data-analysis/errcode"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUserExists 手机号或邮箱已被注册
+	ErrUserExists = errcode.New(errcode.AccountExists, "")
+	// ErrInvalidCredentials 账号或密码错误
+	ErrInvalidCredentials = errcode.New(errcode.InvalidCredentials, "")
+)
+
+// Account roles
+const (
+	RoleStudent   = "student"
+	RoleParent    = "parent"
+	RoleCounselor = "counselor"
+	RoleAdmin     = "admin"
+)
+
+// User represents a registered account
+type User struct {
+	ID           string         `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Phone        *string        `gorm:"type:varchar(20);uniqueIndex" json:"phone,omitempty"`
+	Email        *string        `gorm:"type:varchar(255);uniqueIndex" json:"email,omitempty"`
+	Nickname     string         `gorm:"type:varchar(100)" json:"nickname"`
+	Role         string         `gorm:"type:varchar(20);not null;default:student" json:"role"`
+	PasswordHash string         `gorm:"type:varchar(255);not null" json:"-"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// RegisterRequest represents the API request to sign up
+type RegisterRequest struct {
+	Phone    string `json:"phone,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Nickname string `json:"nickname,omitempty"`
+	// Role is student or parent; counselor and admin roles are assigned by an admin
+	Role string `json:"role,omitempty" binding:"omitempty,oneof=student parent"`
+}
+
+// SetUserRoleRequest represents the admin request to change an account's role
+type SetUserRoleRequest struct {
+	// Account is the phone number or email of the account
+	Account string `json:"account" binding:"required"`
+	Role    string `json:"role" binding:"required,oneof=student parent counselor admin"`
+}
+
+// LoginRequest represents the API request to sign in with a phone number or email
+type LoginRequest struct {
+	Account  string `json:"account" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// AuthTokenResponse represents the token returned after sign-up or sign-in
+type AuthTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      *User     `json:"user"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
+	if u.ID == "" {
+		u.ID = uuid.New().String()
+	}
+	return
+}
+
+// ==================== Database Operations ====================
+
+// RegisterUser creates a new account with a bcrypt-hashed password
+func RegisterUser(ctx context.Context, request *RegisterRequest) (*User, error) {
+	phone := strings.TrimSpace(request.Phone)
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	if phone == "" && email == "" {
+		return nil, errcode.New(errcode.InvalidRequest, "必须提供手机号或邮箱")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	role := request.Role
+	if role == "" {
+		role = RoleStudent
+	}
+
+	user := &User{
+		Nickname:     request.Nickname,
+		Role:         role,
+		PasswordHash: string(hash),
+	}
+	if phone != "" {
+		user.Phone = &phone
+	}
+	if email != "" {
+		user.Email = &email
+	}
+
+	db := database.GetDB().WithContext(ctx)
+	var count int64
+	query := db.Model(&User{})
+	switch {
+	case phone != "" && email != "":
+		query = query.Where("phone = ? OR email = ?", phone, email)
+	case phone != "":
+		query = query.Where("phone = ?", phone)
+	default:
+		query = query.Where("email = ?", email)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrUserExists
+	}
+
+	if result := db.Create(user); result.Error != nil {
+		return nil, result.Error
+	}
+	return user, nil
+}
+
+// AuthenticateUser verifies an account (phone or email) and password
+func AuthenticateUser(ctx context.Context, account, password string) (*User, error) {
+	user, err := GetUserByAccount(ctx, account)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// HasRole reports whether the account has any of the given roles
+func (u *User) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserByAccount retrieves an account by phone number or email
+func GetUserByAccount(ctx context.Context, account string) (*User, error) {
+	account = strings.TrimSpace(account)
+	var user User
+	db := database.GetDB().WithContext(ctx)
+	if result := db.Where("phone = ? OR email = ?", account, strings.ToLower(account)).First(&user); result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// GetUserByID retrieves an account by ID
+func GetUserByID(ctx context.Context, id string) (*User, error) {
+	var user User
+	db := database.GetDB().WithContext(ctx)
+	if result := db.First(&user, "id = ?", id); result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// SetUserRole changes the role of an account identified by phone number or email
+func SetUserRole(ctx context.Context, account, role string) (*User, error) {
+	switch role {
+	case RoleStudent, RoleParent, RoleCounselor, RoleAdmin:
+	default:
+		return nil, errcode.Newf(errcode.InvalidRequest, "无效的角色: %s", role)
+	}
+
+	user, err := GetUserByAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	db := database.GetDB().WithContext(ctx)
+	if err := db.Model(user).Update("role", role).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -1,20 +1,64 @@
 package models
 
-import "strings"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gaokao-data-analysis/errcode"
+
+	"gorm.io/gorm"
+)
 
 // ProfileManager 用户档案管理器
 type ProfileManager struct{}
 
-// ApplyProfileToRequest 将用户档案应用到请求中
-func (pm *ProfileManager) ApplyProfileToRequest(profileID string, req interface{}) error {
+// ApplyProfileToRequest 将用户档案应用到请求中，userID 为当前用户（匿名时为空），需要对档案有读权限
+func (pm *ProfileManager) ApplyProfileToRequest(ctx context.Context, profileID, userID string, req interface{}) error {
 	if profileID == "" {
 		return nil
 	}
 
-	profile, err := GetUserProfileByID(profileID)
-	if err != nil || profile == nil {
+	profile, err := GetUserProfileByID(ctx, profileID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errcode.New(errcode.ProfileNotFound, "")
+	}
+	if err != nil {
+		return err
+	}
+	access, err := GetProfileAccess(ctx, profile, userID)
+	if err != nil {
 		return err
 	}
+	if access < ProfileAccessRead {
+		return ErrProfileForbidden
+	}
+
+	// 指定了快照时，使用快照的分数、等效位次和科目代替档案当前值
+	var snapshotID uint
+	switch r := req.(type) {
+	case *VoluntaryUniversityPriorityRequest:
+		snapshotID = r.SnapshotID
+	case *VoluntaryMajorGroupRequest:
+		snapshotID = r.SnapshotID
+	}
+	if snapshotID > 0 {
+		snapshot, err := GetProfileSnapshot(ctx, profile.ID, snapshotID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errcode.New(errcode.ProfileNotFound, "快照不存在")
+		}
+		if err != nil {
+			return fmt.Errorf("加载档案快照失败: %w", err)
+		}
+		rank, err := snapshot.EquivalentRank(ctx, profile.Province)
+		if err != nil {
+			return fmt.Errorf("换算快照等效位次失败: %w", err)
+		}
+		profile.Score = snapshot.Score
+		profile.Rank = rank
+		profile.Subjects = snapshot.Subjects
+	}
 
 	// 根据请求类型应用档案信息
 	switch r := req.(type) {
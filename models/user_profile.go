@@ -1,20 +1,31 @@
 package models
 
 import (
+	"context"
+	"crypto/subtle"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"gaokao-data-analysis/database"
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/i18n"
+	"gaokao-data-analysis/metrics"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ErrProfileForbidden is returned when a user may not access or claim a profile
+var ErrProfileForbidden = errcode.New(errcode.ProfileForbidden, "")
+
 // UserProfile represents a user's profile in the system
 type UserProfile struct {
 	ID         string         `gorm:"type:varchar(36);primaryKey" json:"id"`
+	OwnerID    *string        `gorm:"type:varchar(36);index" json:"owner_id,omitempty"`
+	ClaimToken *string        `gorm:"type:varchar(64)" json:"-"`
 	Username   string         `gorm:"type:varchar(100);not null" json:"username"`
 	Gender     *string        `gorm:"type:varchar(20)" json:"gender,omitempty"`
 	Province   string         `gorm:"type:varchar(50);not null" json:"province"`
@@ -25,6 +36,8 @@ type UserProfile struct {
 	CreatedAt  time.Time      `json:"created_at"`
 	UpdatedAt  time.Time      `json:"updated_at"`
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	// Warnings 保存时的校验提示，不落库
+	Warnings []string `gorm:"-" json:"warnings,omitempty"`
 }
 
 // Preference defines a user's preferences
@@ -90,21 +103,50 @@ type UserProfileRequest struct {
 }
 
 // APIResponse represents a standard API response
+// Code is 200 on success and the HTTP status otherwise
 type APIResponse struct {
 	Code int32       `json:"code"`
 	Data interface{} `json:"data"`
 	Msg  string      `json:"msg"`
+	// ErrorCode 稳定的机器可读错误码，仅在错误响应中返回，取值见 errcode 包
+	ErrorCode string `json:"error_code,omitempty"`
+	// TraceID 请求的 trace ID（X-Request-ID），仅在错误响应中返回，用于与日志关联
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 // ProfileIDResponse represents the profile ID response
 type ProfileIDResponse struct {
 	ProfileID *string `json:"profile_id,omitempty"`
+	// ClaimToken is only returned for guest profiles and is needed to claim them after sign-up
+	ClaimToken *string  `json:"claim_token,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// ClaimProfileRequest represents the API request to claim a guest profile
+type ClaimProfileRequest struct {
+	ClaimToken string `json:"claim_token" binding:"required"`
 }
 
 // ==================== Database Operations ====================
 
-// CreateUserProfile creates a new user profile in the database
-func CreateUserProfile(request *UserProfileRequest) (*UserProfile, error) {
+// CreateUserProfile creates a new user profile in the database on behalf of userID.
+// An empty userID creates a guest profile that can be claimed later with its claim token;
+// a counselor creates a claimable profile for a student and is linked to it as a member.
+func CreateUserProfile(ctx context.Context, request *UserProfileRequest, userID string) (*UserProfile, error) {
+	// Validate and reconcile score/rank against the score-rank table
+	warnings, err := ValidateProfileRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var creator *User
+	if userID != "" {
+		if creator, err = GetUserByID(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	managed := creator != nil && creator.HasRole(RoleCounselor)
+
 	// Convert request to UserProfile
 	userProfile := &UserProfile{
 		Username: request.Username,
@@ -113,6 +155,14 @@ func CreateUserProfile(request *UserProfileRequest) (*UserProfile, error) {
 		Score:    request.Score,
 		Rank:     request.Rank,
 		Subjects: request.Subjects,
+		Warnings: warnings,
+	}
+
+	if creator != nil && !managed {
+		userProfile.OwnerID = &creator.ID
+	} else {
+		claimToken := strings.ReplaceAll(uuid.New().String(), "-", "")
+		userProfile.ClaimToken = &claimToken
 	}
 
 	// Set preference if provided
@@ -126,24 +176,132 @@ func CreateUserProfile(request *UserProfileRequest) (*UserProfile, error) {
 	}
 
 	// Save to database
-	db := database.GetDB()
-	if result := db.Create(userProfile); result.Error != nil {
+	db := database.GetDB().WithContext(ctx)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(userProfile).Error; err != nil {
+			return err
+		}
+		if managed {
+			return tx.Create(&ProfileMember{
+				ProfileID: userProfile.ID,
+				UserID:    creator.ID,
+				Relation:  RelationCounselor,
+			}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.ProfilesCreated.Inc()
+
+	return userProfile, nil
+}
+
+// UpdateUserProfile validates the request and overwrites an existing user profile on behalf of userID
+func UpdateUserProfile(ctx context.Context, id, userID string, request *UserProfileRequest) (*UserProfile, error) {
+	userProfile, err := GetUserProfileByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	access, err := GetProfileAccess(ctx, userProfile, userID)
+	if err != nil {
+		return nil, err
+	}
+	if access < ProfileAccessWrite {
+		return nil, ErrProfileForbidden
+	}
+
+	warnings, err := ValidateProfileRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	userProfile.Username = request.Username
+	userProfile.Gender = request.Gender
+	userProfile.Province = request.Province
+	userProfile.Score = request.Score
+	userProfile.Rank = request.Rank
+	userProfile.Subjects = request.Subjects
+	if request.Preference != nil {
+		userProfile.Preference = *request.Preference
+	}
+
+	db := database.GetDB().WithContext(ctx)
+	if result := db.Save(userProfile); result.Error != nil {
 		return nil, result.Error
 	}
 
+	userProfile.Warnings = warnings
 	return userProfile, nil
 }
 
 // GetUserProfileByID retrieves a user profile by ID
-func GetUserProfileByID(id string) (*UserProfile, error) {
+func GetUserProfileByID(ctx context.Context, id string) (*UserProfile, error) {
 	var userProfile UserProfile
-	db := database.GetDB()
+	db := database.GetDB().WithContext(ctx)
 	if result := db.First(&userProfile, "id = ?", id); result.Error != nil {
 		return nil, result.Error
 	}
 	return &userProfile, nil
 }
 
+// ListUserProfilesByOwner retrieves all profiles owned by a user
+func ListUserProfilesByOwner(ctx context.Context, ownerID string) ([]UserProfile, error) {
+	var profiles []UserProfile
+	db := database.GetDB().WithContext(ctx)
+	if result := db.Where("owner_id = ?", ownerID).Order("created_at DESC").Find(&profiles); result.Error != nil {
+		return nil, result.Error
+	}
+	return profiles, nil
+}
+
+// ClaimUserProfile transfers a guest profile to a signed-up user
+func ClaimUserProfile(ctx context.Context, id, userID, claimToken string) (*UserProfile, error) {
+	userProfile, err := GetUserProfileByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if userProfile.OwnerID != nil || userProfile.ClaimToken == nil ||
+		subtle.ConstantTimeCompare([]byte(*userProfile.ClaimToken), []byte(claimToken)) != 1 {
+		return nil, ErrProfileForbidden
+	}
+
+	userProfile.OwnerID = &userID
+	userProfile.ClaimToken = nil
+	db := database.GetDB().WithContext(ctx)
+	if result := db.Save(userProfile); result.Error != nil {
+		return nil, result.Error
+	}
+	return userProfile, nil
+}
+
+// Completion returns how much of the profile form has been filled in, as a percentage
+func (u *UserProfile) Completion() int32 {
+	fields := []bool{
+		u.Username != "",
+		u.Gender != nil && *u.Gender != "",
+		u.Province != "",
+		u.Score > 0,
+		u.Rank > 0,
+		len(u.Subjects) > 0,
+		len(u.Preference.CareerInterest) > 0,
+		len(u.Preference.GraduationPlan) > 0,
+		len(u.Preference.MajorPreference) > 0,
+		len(u.Preference.RegionPreference) > 0,
+		len(u.Preference.TargetUniversities) > 0,
+		u.Preference.TuitionPreference != nil && *u.Preference.TuitionPreference != "",
+	}
+
+	filled := 0
+	for _, ok := range fields {
+		if ok {
+			filled++
+		}
+	}
+	return int32(filled * 100 / len(fields))
+}
+
 // ==================== Response Helpers ====================
 
 // SuccessResponse creates a successful API response
@@ -155,11 +313,12 @@ func SuccessResponse(data interface{}, msg string) *APIResponse {
 	}
 }
 
-// ErrorResponse creates an error API response
-func ErrorResponse(code int32, msg string) *APIResponse {
+// ErrorResponse creates an error API response from a catalogued error in the given language
+func ErrorResponse(err *errcode.Error, lang i18n.Lang) *APIResponse {
 	return &APIResponse{
-		Code: code,
-		Msg:  msg,
-		Data: nil,
+		Code:      int32(err.Code.Status()),
+		Msg:       err.Message(lang),
+		ErrorCode: string(err.Code),
+		Data:      nil,
 	}
 }
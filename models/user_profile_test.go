@@ -0,0 +1,109 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"gaokao-data-analysis/database"
+)
+
+// setupTestDB 使用临时目录中的 SQLite 数据库初始化连接，并创建用户、档案相关的表
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	cfg := database.DefaultConfig()
+	cfg.Type = "sqlite"
+	cfg.Path = filepath.Join(t.TempDir(), "test.db")
+	cfg.AdmissionBackend = database.AdmissionBackendSQL
+	cfg.ConnectRetries = 0
+	if err := database.InitDatabase(&cfg, nil); err != nil {
+		t.Fatalf("InitDatabase() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.GetDB().AutoMigrate(&User{}, &UserProfile{}, &ProfileMember{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+}
+
+// createTestUser 创建指定角色的用户
+func createTestUser(t *testing.T, role string) *User {
+	t.Helper()
+	user := &User{Role: role, PasswordHash: "-"}
+	if err := database.GetDB().Create(user).Error; err != nil {
+		t.Fatalf("create user error = %v", err)
+	}
+	return user
+}
+
+// createTestProfile 以 userID 的身份创建档案，userID 为空时创建游客档案
+func createTestProfile(t *testing.T, userID string) *UserProfile {
+	t.Helper()
+	seedScoreRankCache(t)
+	profile, err := CreateUserProfile(context.Background(), &UserProfileRequest{
+		Username: "张三",
+		Province: testScoreRankProvince,
+		Subjects: []string{"物理", "化学", "生物"},
+		Score:    600,
+	}, userID)
+	if err != nil {
+		t.Fatalf("CreateUserProfile() error = %v", err)
+	}
+	return profile
+}
+
+func TestCreateUserProfileOwnership(t *testing.T) {
+	setupTestDB(t)
+	student := createTestUser(t, RoleStudent)
+
+	guest := createTestProfile(t, "")
+	if guest.OwnerID != nil || guest.ClaimToken == nil {
+		t.Errorf("guest profile owner = %v, claim token set = %v, want no owner and a claim token", guest.OwnerID, guest.ClaimToken != nil)
+	}
+
+	owned := createTestProfile(t, student.ID)
+	if owned.OwnerID == nil || *owned.OwnerID != student.ID || owned.ClaimToken != nil {
+		t.Errorf("owned profile owner = %v, claim token set = %v, want owner %s and no claim token", owned.OwnerID, owned.ClaimToken != nil, student.ID)
+	}
+}
+
+func TestClaimUserProfile(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	student := createTestUser(t, RoleStudent)
+	other := createTestUser(t, RoleStudent)
+
+	guest := createTestProfile(t, "")
+	claimToken := *guest.ClaimToken
+	owned := createTestProfile(t, student.ID)
+
+	tests := []struct {
+		name      string
+		profileID string
+		userID    string
+		token     string
+		wantErr   error
+	}{
+		{name: "认领令牌错误", profileID: guest.ID, userID: student.ID, token: "wrong", wantErr: ErrProfileForbidden},
+		{name: "认领令牌为空", profileID: guest.ID, userID: student.ID, token: "", wantErr: ErrProfileForbidden},
+		{name: "认领游客档案", profileID: guest.ID, userID: student.ID, token: claimToken},
+		{name: "已认领的档案不能再次认领", profileID: guest.ID, userID: other.ID, token: claimToken, wantErr: ErrProfileForbidden},
+		{name: "已有归属的档案不能认领", profileID: owned.ID, userID: other.ID, token: claimToken, wantErr: ErrProfileForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := ClaimUserProfile(ctx, tt.profileID, tt.userID, tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ClaimUserProfile() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if profile.OwnerID == nil || *profile.OwnerID != tt.userID || profile.ClaimToken != nil {
+				t.Errorf("ClaimUserProfile() owner = %v, claim token set = %v, want owner %s", profile.OwnerID, profile.ClaimToken != nil, tt.userID)
+			}
+		})
+	}
+}
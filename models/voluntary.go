@@ -7,7 +7,7 @@ import (
 	"strings"
 	"time"
 
-	"gaokao-data-analysis/database"
+	"gaokao-data-analysis/errcode"
 )
 
 var (
@@ -28,6 +28,10 @@ type VoluntaryUniversityPriorityRequest struct {
 	MinScore string `json:"min_score,omitempty" form:"min_score"`
 	// 档案id
 	ProfileID string `json:"profile_id,omitempty" form:"profile_id"`
+	// 档案快照id，指定时按该次考试的分数推荐
+	SnapshotID uint `json:"snapshot_id,omitempty" form:"snapshot_id"`
+	// 当前用户ID，由处理器根据令牌设置，用于校验档案的访问权限
+	UserID string `json:"-" form:"-"`
 	// 报考的省份
 	Province string `json:"province,omitempty" form:"province"`
 	// 排名
@@ -38,6 +42,8 @@ type VoluntaryUniversityPriorityRequest struct {
 	Strategy int32 `json:"strategy,omitempty" form:"strategy"`
 	// 用户选择的科目
 	Subjects string `json:"subjects,omitempty" form:"subjects"`
+	// 返回的历年录取数据年数，默认3，最多5
+	HistoryYears int32 `json:"history_years,omitempty" form:"history_years"`
 	// 分页参数
 	Page     int32 `json:"page,omitempty" form:"page"`
 	PageSize int32 `json:"page_size,omitempty" form:"page_size"`
@@ -64,16 +70,47 @@ type VoluntaryMajorGroupRequest struct {
 	GroupCode string `json:"group_code,omitempty" form:"group_code"`
 	// 档案id
 	ProfileID string `json:"profile_id,omitempty" form:"profile_id"`
+	// 档案快照id，指定时按该次考试的分数推荐
+	SnapshotID uint `json:"snapshot_id,omitempty" form:"snapshot_id"`
+	// 当前用户ID，由处理器根据令牌设置，用于校验档案的访问权限
+	UserID string `json:"-" form:"-"`
 	// 报考的省份
 	Province string `json:"province,omitempty" form:"province"`
 	// 排名
 	Rank int32 `json:"rank,omitempty" form:"rank"`
 	// 分数
 	Score int32 `json:"score,omitempty" form:"score"`
-	// [0冲、1稳、2保]，默认1稳
-	Strategy int32 `json:"strategy,omitempty" form:"strategy"`
+	// 单个策略 [0冲、1稳、2保]，兼容旧参数，等价于 strategies 只包含该策略
+	Strategy *int32 `json:"strategy,omitempty" form:"strategy"`
+	// 只返回这些策略的专业，可多选 [0冲、1稳、2保]，不传时返回全部专业
+	Strategies []int32 `json:"strategies,omitempty" form:"strategies"`
+	// 策略筛选后没有专业时返回专业组全部专业，否则返回空列表
+	IncludeAll bool `json:"include_all,omitempty" form:"include_all"`
 	// 用户选择的科目
 	Subjects string `json:"subjects,omitempty" form:"subjects"`
+	// 返回的历年录取数据年数，默认3，最多5
+	HistoryYears int32 `json:"history_years,omitempty" form:"history_years"`
+}
+
+// strategyFilter 返回去重后的策略筛选条件，为空表示不筛选
+func (req *VoluntaryMajorGroupRequest) strategyFilter() ([]int32, error) {
+	strategies := req.Strategies
+	if len(strategies) == 0 && req.Strategy != nil {
+		strategies = []int32{*req.Strategy}
+	}
+
+	var result []int32
+	seen := make(map[int32]bool)
+	for _, strategy := range strategies {
+		if strategy < 0 || strategy > 2 {
+			return nil, errcode.Newf(errcode.InvalidStrategy, "无效的策略: %d", strategy)
+		}
+		if !seen[strategy] {
+			seen[strategy] = true
+			result = append(result, strategy)
+		}
+	}
+	return result, nil
 }
 
 // ValidateSubjects 验证科目组合，必须包含物理或历史
@@ -183,6 +220,24 @@ func (em *EnumMapper) MapOwnership(ownership string) (int, bool) {
 	return val, exists
 }
 
+// MapEducationLevel 映射办学层次枚举值
+func (em *EnumMapper) MapEducationLevel(level string) (int, bool) {
+	val, exists := em.educationMap[level]
+	return val, exists
+}
+
+// MapAdmissionBatch 映射录取批次枚举值
+func (em *EnumMapper) MapAdmissionBatch(batch string) (int, bool) {
+	val, exists := em.admissionMap[batch]
+	return val, exists
+}
+
+// MapEnrollmentType 映射招生类型枚举值，普通类型为空字符串
+func (em *EnumMapper) MapEnrollmentType(enrollmentType string) (int, bool) {
+	val, exists := em.enrollmentMap[enrollmentType]
+	return val, exists
+}
+
 // MapSubjectCategory 映射科目类别枚举值
 func (em *EnumMapper) MapSubjectCategory(category string) (int, bool) {
 	val, exists := em.subjectCatMap[category]
@@ -210,10 +265,10 @@ func (src *ScoreRangeCalculator) CalculateRange(userScore int32, strategy int32)
 func GetUniversityPriorityVoluntary(ctx context.Context, req *VoluntaryUniversityPriorityRequest) (*paginationData, error) {
 	startTime := time.Now()
 
-	// 获取ClickHouse连接
-	db := database.GetClickHouse()
-	if db == nil {
-		return nil, fmt.Errorf("ClickHouse连接未初始化")
+	// 获取录取数据查询实现
+	repo, err := GetAdmissionRepository()
+	if err != nil {
+		return nil, err
 	}
 
 	// 初始化辅助器
@@ -222,89 +277,56 @@ func GetUniversityPriorityVoluntary(ctx context.Context, req *VoluntaryUniversit
 	scoreCalculator := &ScoreRangeCalculator{}
 
 	// 应用用户档案信息
-	if err := profileManager.ApplyProfileToRequest(req.ProfileID, req); err != nil {
-		slog.Warn("应用用户档案失败", "error", err.Error())
+	if err := profileManager.ApplyProfileToRequest(ctx, req.ProfileID, req.UserID, req); err != nil {
+		return nil, err
 	}
 
 	// 验证科目组合
 	if err := ValidateSubjects(req.Subjects); err != nil {
-		return nil, fmt.Errorf("科目验证失败: %w", err)
+		return nil, errcode.New(errcode.InvalidSubjects, err.Error())
 	}
 
-	// 构建基础查询
-	baseQuery := fmt.Sprintf(`SELECT 
-	school_code as recruit_code,
-	school_name as university_name,
-	school_province as province,
-	school_type as category,
-	school_tags as tags,
-	major_group_code as group_code,
-	count(*) as major_count
-FROM %s
-WHERE 1=1
-`, TABLE)
-
-	queryBuilder := NewQueryBuilder(baseQuery)
+	filter := &UniversityFilter{}
 
 	// 处理省份条件
 	if req.Province != "" {
 		if provinceVal, exists := enumMapper.MapProvince(req.Province); exists {
-			queryBuilder.AddCondition("source_province = ?", provinceVal)
+			filter.SourceProvince = provinceVal
 		}
 	}
 
-	// 处理分数范围条件
+	// 处理分数范围条件，使用该省份已有录取分数的最新年份
 	if req.Score > 0 {
 		minDiff, maxDiff := scoreCalculator.CalculateRange(req.Score, req.Strategy)
-		queryBuilder.AddCondition("min_score_2024 >= ?", req.Score+minDiff)
-		queryBuilder.AddCondition("min_score_2024 <= ?", req.Score+maxDiff)
+		filter.MinScore = req.Score + minDiff
+		filter.MaxScore = req.Score + maxDiff
+		filter.ScoreYear, err = repo.LatestAdmissionYear(ctx, filter.SourceProvince)
+		if err != nil {
+			return nil, fmt.Errorf("查询最新录取年份失败: %w", err)
+		}
 	}
 
 	// 处理科目条件
 	if req.Subjects != "" {
 		subjectFilter, err := ParseSubjects(req.Subjects)
 		if err != nil {
-			return nil, fmt.Errorf("解析科目失败: %w", err)
+			return nil, errcode.New(errcode.InvalidSubjects, err.Error())
 		}
-		subjectConditions, subjectArgs := subjectFilter.BuildSubjectConditions()
-		queryBuilder.AddConditions(subjectConditions, subjectArgs)
+		filter.Subjects = subjectFilter
 	}
 
 	// 处理城市筛选
 	if req.Citys != "" {
-		cities := strings.Split(req.Citys, ",")
-		if len(cities) > 0 {
-			citiesCondition := make([]string, len(cities))
-			var cityArgs []interface{}
-			for i, city := range cities {
-				citiesCondition[i] = "school_city = ?"
-				cityArgs = append(cityArgs, strings.TrimSpace(city))
-			}
-			queryBuilder.AddCondition("("+strings.Join(citiesCondition, " OR ")+")", cityArgs...)
+		for _, city := range strings.Split(req.Citys, ",") {
+			filter.Cities = append(filter.Cities, strings.TrimSpace(city))
 		}
 	}
 
 	// 处理院校类型筛选
 	if req.CollegeType != "" {
-		if err := buildCollegeTypeConditions(queryBuilder, enumMapper, req.CollegeType); err != nil {
-			return nil, fmt.Errorf("构建院校类型条件失败: %w", err)
-		}
+		applyCollegeTypeFilter(filter, enumMapper, req.CollegeType)
 	}
 
-	// 添加分组和排序
-	finalQuery, args := queryBuilder.Build()
-	finalQuery += `
-		GROUP BY 
-			recruit_code, 
-			university_name, 
-			province, 
-			category,
-			tags,
-			group_code
-		ORDER BY 
-			university_name ASC
-	`
-
 	// 处理分页
 	if req.Page <= 0 {
 		req.Page = 1
@@ -314,18 +336,15 @@ WHERE 1=1
 	}
 
 	// 查询总数
-	total, err := queryUniversityCount(ctx, db, queryBuilder)
+	total, err := repo.CountUniversities(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("查询总数失败: %w", err)
 	}
 
-	// 添加分页限制
+	// 执行分页查询
 	limit := req.PageSize
 	offset := (req.Page - 1) * req.PageSize
-	paginatedQuery := finalQuery + fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
-
-	// 执行分页查询
-	resultItems, err := executeUniversityQuery(ctx, db, paginatedQuery, args, req)
+	resultItems, err := executeUniversityQuery(ctx, repo, filter, limit, offset, req)
 	if err != nil {
 		return nil, fmt.Errorf("执行查询失败: %w", err)
 	}
@@ -342,7 +361,7 @@ WHERE 1=1
 		Total:    int32(total),
 	}
 
-	slog.Info("志愿-院校优先查询完成",
+	slog.InfoContext(ctx, "志愿-院校优先查询完成",
 		"totalResults", total,
 		"duration", time.Since(startTime).String(),
 		"page", req.Page,
@@ -353,46 +372,20 @@ WHERE 1=1
 	return data, nil
 }
 
-// buildCollegeTypeConditions 构建院校类型筛选条件
-func buildCollegeTypeConditions(qb *QueryBuilder, em *EnumMapper, collegeType string) error {
-	collegeTypes := strings.Split(collegeType, ",")
-	if len(collegeTypes) == 0 {
-		return nil
-	}
-
-	var ownershipConditions []string
-	var ownershipArgs []interface{}
-	var tagsConditions []string
-	var tagsArgs []interface{}
-
-	for _, colType := range collegeTypes {
+// applyCollegeTypeFilter 解析院校类型筛选，办学性质以外的取值视为院校标签
+func applyCollegeTypeFilter(filter *UniversityFilter, em *EnumMapper, collegeType string) {
+	for _, colType := range strings.Split(collegeType, ",") {
 		colType = strings.TrimSpace(colType)
+		if colType == "" {
+			continue
+		}
 
 		// 检查是否是办学性质
 		if ownershipVal, exists := em.MapOwnership(colType); exists {
-			ownershipConditions = append(ownershipConditions, "school_ownership = ?")
-			ownershipArgs = append(ownershipArgs, ownershipVal)
+			filter.Ownerships = append(filter.Ownerships, ownershipVal)
 		} else {
 			// 其他为院校标签
-			tagsConditions = append(tagsConditions, "positionUTF8(school_tags, ?) > 0")
-			tagsArgs = append(tagsArgs, colType)
+			filter.Tags = append(filter.Tags, colType)
 		}
 	}
-
-	// 合并各类条件
-	var typeConditions []string
-	if len(ownershipConditions) > 0 {
-		typeConditions = append(typeConditions, "("+strings.Join(ownershipConditions, " OR ")+")")
-		qb.args = append(qb.args, ownershipArgs...)
-	}
-	if len(tagsConditions) > 0 {
-		typeConditions = append(typeConditions, "("+strings.Join(tagsConditions, " OR ")+")")
-		qb.args = append(qb.args, tagsArgs...)
-	}
-
-	if len(typeConditions) > 0 {
-		qb.AddCondition("(" + strings.Join(typeConditions, " AND ") + ")")
-	}
-
-	return nil
 }
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreAllow(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Rate: 1.0 / 60, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Allow(ctx, "ip:1.2.3.4", limit)
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		if !result.Allowed || result.Remaining != i {
+			t.Fatalf("Allow() = %+v, want allowed with %d remaining", result, i)
+		}
+	}
+
+	result, err := store.Allow(ctx, "ip:1.2.3.4", limit)
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if result.Allowed {
+		t.Fatalf("Allow() = %+v, want rejected after burst is used up", result)
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > time.Minute {
+		t.Errorf("Allow() RetryAfter = %v, want within one token interval", result.RetryAfter)
+	}
+
+	// 不同 key 使用各自的令牌桶
+	if result, _ := store.Allow(ctx, "ip:5.6.7.8", limit); !result.Allowed {
+		t.Errorf("Allow() for another key = %+v, want allowed", result)
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Rate: 1, Burst: 1}
+
+	if result, _ := store.Allow(ctx, "user:1", limit); !result.Allowed {
+		t.Fatalf("Allow() = %+v, want allowed", result)
+	}
+	// 模拟经过一秒，补充一个令牌
+	store.buckets["user:1"].last = time.Now().Add(-time.Second)
+	if result, _ := store.Allow(ctx, "user:1", limit); !result.Allowed {
+		t.Errorf("Allow() after refill = %+v, want allowed", result)
+	}
+}
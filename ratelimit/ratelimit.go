@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// 限流存储后端
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Limit 令牌桶参数，Rate 为每秒补充的令牌数，Burst 为桶容量
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute 按每分钟请求数创建限制，burst 小于 1 时取 1
+func PerMinute(requests, burst int) Limit {
+	if requests <= 0 {
+		return Limit{}
+	}
+	return Limit{Rate: float64(requests) / 60, Burst: max(burst, 1)}
+}
+
+// Enabled 是否启用该限制，速率为 0 表示不限流
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result 一次令牌申请的结果
+type Result struct {
+	// Allowed 是否放行
+	Allowed bool
+	// Remaining 放行后桶内剩余的完整令牌数
+	Remaining int
+	// RetryAfter 被拒绝时距离下一个令牌可用的时间
+	RetryAfter time.Duration
+}
+
+// Store 令牌桶存储，同一 key 的请求共享一个桶
+type Store interface {
+	// Allow 从 key 对应的桶中取一个令牌
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+	// Close 释放存储占用的资源
+	Close() error
+}
+
+// Policy 一组路由的限流策略：已登录用户按用户限流，匿名请求按 IP 限流
+type Policy struct {
+	Name    string
+	PerIP   Limit
+	PerUser Limit
+}
+
+// PolicyConfig 一个策略的配置，IP 每分钟请求数为 0 时不限流；用户每分钟请求数为 0 时已登录用户按 IP 限流
+type PolicyConfig struct {
+	IPPerMinute   int `yaml:"ip_per_minute" env:"IP_PER_MINUTE"`
+	UserPerMinute int `yaml:"user_per_minute" env:"USER_PER_MINUTE"`
+	Burst         int `yaml:"burst" env:"BURST"`
+}
+
+// Config 限流配置
+type Config struct {
+	// Enabled 是否启用限流
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// Backend 存储后端: memory, redis
+	Backend string `yaml:"backend" env:"RATE_LIMIT_BACKEND"`
+	// RedisURL Redis 兼容服务的连接地址，如 redis://:password@localhost:6379/0
+	RedisURL string `yaml:"redis_url" env:"RATE_LIMIT_REDIS_URL" secret:"true"`
+
+	// 内置策略，环境变量名为 RATE_LIMIT_<NAME>_IP_PER_MINUTE、RATE_LIMIT_<NAME>_USER_PER_MINUTE 和 RATE_LIMIT_<NAME>_BURST
+	// Voluntary 志愿推荐每次请求包含多次 ClickHouse 查询，限制最严
+	Voluntary PolicyConfig `yaml:"voluntary" envPrefix:"RATE_LIMIT_VOLUNTARY_"`
+	// Auth 登录注册，防止暴力破解
+	Auth PolicyConfig `yaml:"auth" envPrefix:"RATE_LIMIT_AUTH_"`
+	// Default 其余接口
+	Default PolicyConfig `yaml:"default" envPrefix:"RATE_LIMIT_DEFAULT_"`
+}
+
+// DefaultConfig 返回默认限流配置
+func DefaultConfig() Config {
+	return Config{
+		Enabled:   true,
+		Backend:   BackendMemory,
+		RedisURL:  "redis://localhost:6379/0",
+		Voluntary: PolicyConfig{IPPerMinute: 30, UserPerMinute: 60, Burst: 10},
+		Auth:      PolicyConfig{IPPerMinute: 10, UserPerMinute: 0, Burst: 5},
+		Default:   PolicyConfig{IPPerMinute: 300, UserPerMinute: 600, Burst: 60},
+	}
+}
+
+// Validate 校验限流配置
+func (cfg *Config) Validate() error {
+	var errs []error
+	switch strings.ToLower(cfg.Backend) {
+	case BackendMemory, BackendRedis:
+	default:
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_BACKEND 无效: %s，可选值为 memory, redis", cfg.Backend))
+	}
+	for _, name := range []string{"voluntary", "auth", "default"} {
+		if p := cfg.policyConfig(name); p.IPPerMinute < 0 || p.UserPerMinute < 0 || p.Burst < 0 {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_%s_* 不能为负数", strings.ToUpper(name)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// policyConfig 返回指定名称的策略配置，未知名称使用 default 策略
+func (cfg *Config) policyConfig(name string) PolicyConfig {
+	switch name {
+	case "voluntary":
+		return cfg.Voluntary
+	case "auth":
+		return cfg.Auth
+	default:
+		return cfg.Default
+	}
+}
+
+// Policy 返回指定名称的限流策略，未知名称使用 default 策略的参数
+func (cfg *Config) Policy(name string) Policy {
+	p := cfg.policyConfig(name)
+	return Policy{
+		Name:    name,
+		PerIP:   PerMinute(p.IPPerMinute, p.Burst),
+		PerUser: PerMinute(p.UserPerMinute, p.Burst),
+	}
+}
+
+// NewStore 按配置创建存储后端
+func NewStore(cfg *Config) (Store, error) {
+	if strings.ToLower(cfg.Backend) == BackendRedis {
+		return NewRedisStore(cfg.RedisURL)
+	}
+	return NewMemoryStore(), nil
+}
+
+// retryAfter 计算令牌不足时等待下一个令牌的时间
+func retryAfter(tokens float64, limit Limit) time.Duration {
+	return time.Duration(math.Ceil((1 - tokens) / limit.Rate * float64(time.Second)))
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import "testing"
+
+func TestPerMinute(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests int
+		burst    int
+		want     Limit
+		enabled  bool
+	}{
+		{name: "每分钟请求数", requests: 30, burst: 10, want: Limit{Rate: 0.5, Burst: 10}, enabled: true},
+		{name: "容量至少为1", requests: 60, burst: 0, want: Limit{Rate: 1, Burst: 1}, enabled: true},
+		{name: "请求数为0时不限流", requests: 0, burst: 10, want: Limit{}, enabled: false},
+		{name: "请求数为负数时不限流", requests: -1, burst: 10, want: Limit{}, enabled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PerMinute(tt.requests, tt.burst)
+			if got != tt.want {
+				t.Errorf("PerMinute(%d, %d) = %+v, want %+v", tt.requests, tt.burst, got, tt.want)
+			}
+			if got.Enabled() != tt.enabled {
+				t.Errorf("PerMinute(%d, %d).Enabled() = %v, want %v", tt.requests, tt.burst, got.Enabled(), tt.enabled)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr bool
+	}{
+		{name: "默认配置", modify: func(cfg *Config) {}},
+		{name: "后端不区分大小写", modify: func(cfg *Config) { cfg.Backend = "Redis" }},
+		{name: "未知后端", modify: func(cfg *Config) { cfg.Backend = "memcached" }, wantErr: true},
+		{name: "请求数为负数", modify: func(cfg *Config) { cfg.Auth.IPPerMinute = -1 }, wantErr: true},
+		{name: "容量为负数", modify: func(cfg *Config) { cfg.Default.Burst = -1 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigPolicy(t *testing.T) {
+	cfg := DefaultConfig()
+
+	auth := cfg.Policy("auth")
+	if auth.Name != "auth" || auth.PerIP != PerMinute(10, 5) || auth.PerUser.Enabled() {
+		t.Errorf("Policy(auth) = %+v, want per-IP limit only", auth)
+	}
+
+	unknown := cfg.Policy("profiles")
+	if unknown.Name != "profiles" || unknown.PerIP != PerMinute(300, 60) || unknown.PerUser != PerMinute(600, 60) {
+		t.Errorf("Policy(profiles) = %+v, want default policy limits", unknown)
+	}
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix Redis 中限流键的前缀
+const keyPrefix = "gaokao:ratelimit:"
+
+// tokenBucketScript 在 Redis 中原子地补充并扣减令牌，使用服务端时间避免各实例时钟不一致
+// 返回 {是否放行, 剩余令牌}，剩余令牌以字符串返回以免被 Redis 截断为整数
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore 基于 Redis 兼容服务（Redis、Valkey、KeyDB 等）的令牌桶存储，多实例共享计数
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore 根据连接地址创建存储，连接在首次请求时建立
+func NewRedisStore(url string) (*RedisStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_REDIS_URL 无效: %w", err)
+	}
+	options.DialTimeout = 2 * time.Second
+	options.ReadTimeout = 500 * time.Millisecond
+	options.WriteTimeout = 500 * time.Millisecond
+	return &RedisStore{client: redis.NewClient(options)}, nil
+}
+
+// Allow 实现 Store
+func (s *RedisStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	values, err := tokenBucketScript.Run(ctx, s.client, []string{keyPrefix + key},
+		strconv.FormatFloat(limit.Rate, 'f', -1, 64), limit.Burst).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("执行限流脚本失败: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("限流脚本返回值格式错误: %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	tokenText, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokenText, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("限流脚本返回值格式错误: %w", err)
+	}
+
+	if allowed == 1 {
+		return Result{Allowed: true, Remaining: int(tokens)}, nil
+	}
+	return Result{RetryAfter: retryAfter(tokens, limit)}, nil
+}
+
+// Close 实现 Store
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
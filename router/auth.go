@@ -1 +1,82 @@
 package routes
+
+import (
+	"errors"
+	"strings"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/handlers"
+	"gaokao-data-analysis/models"
+	"gaokao-data-analysis/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// bearerToken 从 Authorization 头中提取 Bearer 令牌
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// OptionalAuth 解析令牌并写入当前用户，未携带令牌时按匿名（游客）处理
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		userID, err := utils.ParseToken(token)
+		if err != nil {
+			handlers.AbortWithError(c, errcode.Wrap(errcode.InvalidToken, err))
+			return
+		}
+
+		c.Set(handlers.ContextUserIDKey, userID)
+		c.Next()
+	}
+}
+
+// RequireAuth 要求请求携带有效令牌
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			handlers.AbortWithError(c, errcode.New(errcode.Unauthenticated, ""))
+			return
+		}
+
+		userID, err := utils.ParseToken(token)
+		if err != nil {
+			handlers.AbortWithError(c, errcode.Wrap(errcode.InvalidToken, err))
+			return
+		}
+
+		c.Set(handlers.ContextUserIDKey, userID)
+		c.Next()
+	}
+}
+
+// RequireRole 要求当前用户具有指定角色之一，需在 RequireAuth 之后使用
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := models.GetUserByID(c.Request.Context(), c.GetString(handlers.ContextUserIDKey))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errcode.New(errcode.AccountNotFound, "")
+		}
+		if err != nil {
+			handlers.AbortWithError(c, err)
+			return
+		}
+		if !user.HasRole(roles...) {
+			handlers.AbortWithError(c, errcode.New(errcode.Forbidden, ""))
+			return
+		}
+		c.Next()
+	}
+}
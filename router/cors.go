@@ -1 +1,81 @@
 package routes
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gaokao-data-analysis/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// allowOrigin 判断来源是否允许
+func allowOrigin(cfg *config.CORSConfig, origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		// https://*.example.com 匹配 example.com 的任意子域名
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok &&
+			len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS 根据配置处理跨域请求，预检请求直接返回 204，不允许的来源不添加跨域响应头
+func CORS(cfg *config.CORSConfig) gin.HandlerFunc {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		// 响应内容随 Origin 变化，告知缓存按 Origin 区分
+		c.Writer.Header().Add("Vary", "Origin")
+		if !allowOrigin(cfg, origin) {
+			c.Next()
+			return
+		}
+
+		// 允许携带凭证时不能返回 *，回显请求的来源
+		if slices.Contains(cfg.AllowedOrigins, "*") && !cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !preflight {
+			if exposed != "" {
+				c.Header("Access-Control-Expose-Headers", exposed)
+			}
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		c.Header("Access-Control-Allow-Methods", methods)
+		if headers != "" {
+			c.Header("Access-Control-Allow-Headers", headers)
+		}
+		if cfg.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
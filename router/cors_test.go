@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"testing"
+
+	"gaokao-data-analysis/config"
+)
+
+func TestAllowOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{name: "未配置来源", allowed: nil, origin: "https://app.example.com", want: false},
+		{name: "任意来源", allowed: []string{"*"}, origin: "https://app.example.com", want: true},
+		{name: "完全匹配", allowed: []string{"https://app.example.com"}, origin: "https://app.example.com", want: true},
+		{name: "忽略大小写", allowed: []string{"https://app.example.com"}, origin: "HTTPS://APP.EXAMPLE.COM", want: true},
+		{name: "端口不同", allowed: []string{"https://app.example.com"}, origin: "https://app.example.com:8443", want: false},
+		{name: "匹配列表中任意一项", allowed: []string{"https://a.example.com", "https://b.example.com"}, origin: "https://b.example.com", want: true},
+		{name: "通配子域名", allowed: []string{"https://*.example.com"}, origin: "https://app.example.com", want: true},
+		{name: "通配多级子域名", allowed: []string{"https://*.example.com"}, origin: "https://a.b.example.com", want: true},
+		{name: "通配不匹配主域名", allowed: []string{"https://*.example.com"}, origin: "https://example.com", want: false},
+		{name: "通配部分不能为空", allowed: []string{"https://*.example.com"}, origin: "https://.example.com", want: false},
+		{name: "通配不匹配其他协议", allowed: []string{"https://*.example.com"}, origin: "http://app.example.com", want: false},
+		{name: "通配不匹配相似域名", allowed: []string{"https://*.example.com"}, origin: "https://evilexample.com", want: false},
+		{name: "通配不匹配后缀追加", allowed: []string{"https://*.example.com"}, origin: "https://app.example.com.evil.io", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.CORSConfig{AllowedOrigins: tt.allowed}
+			if got := allowOrigin(cfg, tt.origin); got != tt.want {
+				t.Errorf("allowOrigin(%v, %q) = %v, want %v", tt.allowed, tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"gaokao-data-analysis/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Language 根据 Accept-Language 选择响应语言并写入请求 context，默认中文
+// 响应头 Content-Language 为实际使用的语言，Vary 提示缓存按语言区分响应
+func Language() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.Parse(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(i18n.WithLang(c.Request.Context(), lang))
+		c.Header("Content-Language", string(lang))
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
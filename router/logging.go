@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"log/slog"
+	"time"
+
+	"gaokao-data-analysis/logs"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestLogger 将带 trace ID 的记录器写入请求 context，并记录访问日志，需在 RequestID 之后使用
+// 处理函数通过 logs.FromContext(c.Request.Context()) 获取请求范围的记录器
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		logger := logs.FromContext(c.Request.Context())
+
+		ctx := logs.WithLogger(c.Request.Context(), logger)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("clientIP", c.ClientIP()),
+			slog.Int("size", c.Writer.Size()),
+		}
+		// 启用链路追踪时记录 OpenTelemetry trace ID，便于从访问日志跳转到链路
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			attrs = append(attrs, slog.String("otel_trace_id", spanContext.TraceID().String()))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		logger.LogAttrs(ctx, level, "HTTP请求", attrs...)
+	}
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"strconv"
+	"time"
+
+	"gaokao-data-analysis/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsPath Prometheus 指标的访问路径
+const MetricsPath = "/metrics"
+
+// Metrics 按路由统计请求数和请求耗时
+// 路由标签使用路由模板而非实际路径，未匹配路由的请求统一记为 unmatched，避免标签数量无限增长
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsHandler 输出 Prometheus 指标
+func MetricsHandler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
@@ -0,0 +1,102 @@
+package routes
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+
+	"gaokao-data-analysis/errcode"
+	"gaokao-data-analysis/handlers"
+	"gaokao-data-analysis/ratelimit"
+	"gaokao-data-analysis/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 限流相关响应头
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// RateLimiter 按路由组策略限流
+type RateLimiter struct {
+	store ratelimit.Store
+	cfg   *ratelimit.Config
+}
+
+// NewRateLimiter 按配置创建限流器，未启用限流时返回 nil，此时 Group 返回空中间件
+func NewRateLimiter(cfg *ratelimit.Config) (*RateLimiter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	store, err := ratelimit.NewStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("已启用限流", "backend", cfg.Backend)
+	return &RateLimiter{store: store, cfg: cfg}, nil
+}
+
+// Close 关闭存储后端
+func (l *RateLimiter) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.store.Close()
+}
+
+// Group 返回使用指定策略的限流中间件，策略通过 rate_limit.<name> 配置项或 RATE_LIMIT_<NAME>_* 环境变量配置
+// 已登录用户按用户 ID 计数，避免学校机房等共用出口 IP 的用户互相影响；匿名请求和未设置用户限额的策略按客户端 IP 计数
+func (l *RateLimiter) Group(name string) gin.HandlerFunc {
+	if l == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	policy := l.cfg.Policy(name)
+	return func(c *gin.Context) {
+		// 策略未设置用户限额（如登录注册）时，已登录用户同样按 IP 计数，避免携带令牌绕过限流
+		limit, key := policy.PerIP, "ip:"+c.ClientIP()
+		if userID := requestUserID(c); userID != "" && policy.PerUser.Enabled() {
+			limit, key = policy.PerUser, "user:"+userID
+		}
+		if !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		result, err := l.store.Allow(c.Request.Context(), policy.Name+":"+key, limit)
+		if err != nil {
+			// 存储不可用时放行，避免限流组件故障导致服务不可用
+			slog.WarnContext(c.Request.Context(), "限流检查失败，已放行请求", "policy", policy.Name, "error", err.Error())
+			c.Next()
+			return
+		}
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(limit.Burst))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			seconds := int(math.Ceil(result.RetryAfter.Seconds()))
+			c.Header(RetryAfterHeader, strconv.Itoa(seconds))
+			slog.WarnContext(c.Request.Context(), "请求被限流", "policy", policy.Name, "key", key, "retryAfter", seconds)
+			handlers.AbortWithError(c, errcode.Newf(errcode.RateLimited, "%d 秒后可重试", seconds))
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestUserID 返回当前用户 ID，未经过鉴权中间件的路由组自行解析令牌，令牌无效时按匿名处理
+func requestUserID(c *gin.Context) string {
+	if userID := c.GetString(handlers.ContextUserIDKey); userID != "" {
+		return userID
+	}
+	if token := bearerToken(c); token != "" {
+		if userID, err := utils.ParseToken(token); err == nil {
+			return userID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gaokao-data-analysis/handlers"
+	"gaokao-data-analysis/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRateLimitTestEngine 创建使用指定策略限流的测试路由，请求参数 user 模拟已登录用户
+func newRateLimitTestEngine(policy ratelimit.PolicyConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := ratelimit.DefaultConfig()
+	cfg.Default = policy
+	limiter := &RateLimiter{store: ratelimit.NewMemoryStore(), cfg: &cfg}
+
+	r := gin.New()
+	r.GET("/test", func(c *gin.Context) {
+		if userID := c.Query("user"); userID != "" {
+			c.Set(handlers.ContextUserIDKey, userID)
+		}
+		c.Next()
+	}, limiter.Group("default"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestRateLimiterGroup(t *testing.T) {
+	type request struct {
+		user       string
+		remoteAddr string
+		wantStatus int
+	}
+	tests := []struct {
+		name     string
+		policy   ratelimit.PolicyConfig
+		requests []request
+	}{
+		{
+			name:   "匿名请求按 IP 计数",
+			policy: ratelimit.PolicyConfig{IPPerMinute: 1, UserPerMinute: 1, Burst: 1},
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", wantStatus: http.StatusOK},
+				{remoteAddr: "10.0.0.1:1001", wantStatus: http.StatusTooManyRequests},
+				{remoteAddr: "10.0.0.2:1000", wantStatus: http.StatusOK},
+			},
+		},
+		{
+			name:   "已登录用户按用户计数，共用 IP 互不影响",
+			policy: ratelimit.PolicyConfig{IPPerMinute: 1, UserPerMinute: 1, Burst: 1},
+			requests: []request{
+				{user: "a", remoteAddr: "10.0.0.1:1000", wantStatus: http.StatusOK},
+				{user: "b", remoteAddr: "10.0.0.1:1000", wantStatus: http.StatusOK},
+				{user: "a", remoteAddr: "10.0.0.2:1000", wantStatus: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:   "未设置用户限额时已登录用户按 IP 计数",
+			policy: ratelimit.PolicyConfig{IPPerMinute: 1, Burst: 1},
+			requests: []request{
+				{user: "a", remoteAddr: "10.0.0.1:1000", wantStatus: http.StatusOK},
+				{user: "b", remoteAddr: "10.0.0.1:1000", wantStatus: http.StatusTooManyRequests},
+				{remoteAddr: "10.0.0.1:1000", wantStatus: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:   "未设置限额时不限流",
+			policy: ratelimit.PolicyConfig{},
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", wantStatus: http.StatusOK},
+				{remoteAddr: "10.0.0.1:1000", wantStatus: http.StatusOK},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRateLimitTestEngine(tt.policy)
+			for i, req := range tt.requests {
+				httpReq := httptest.NewRequest(http.MethodGet, "/test?user="+req.user, nil)
+				httpReq.RemoteAddr = req.remoteAddr
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httpReq)
+
+				if w.Code != req.wantStatus {
+					t.Fatalf("request %d status = %d, want %d", i, w.Code, req.wantStatus)
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get(RetryAfterHeader) == "" {
+					t.Errorf("request %d missing %s header", i, RetryAfterHeader)
+				}
+			}
+		})
+	}
+}
@@ -1,34 +1,98 @@
 package routes
 
 import (
-	"os"
+	"log/slog"
 
+	"gaokao-data-analysis/config"
 	"gaokao-data-analysis/handlers"
+	"gaokao-data-analysis/models"
+	"gaokao-data-analysis/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRouter sets up the gin router and all routes
-func SetupRouter() *gin.Engine {
-	mode := os.Getenv("GIN_MODE")
-	if mode == "" {
-		mode = gin.ReleaseMode
+// The returned RateLimiter must be closed on shutdown; it is nil when rate limiting is disabled
+func SetupRouter(cfg *config.Config) (*gin.Engine, *RateLimiter) {
+	gin.SetMode(cfg.Server.Mode)
+
+	// 使用 slog 记录访问日志，替代 gin 默认的 Logger
+	r := gin.New()
+
+	// 客户端 IP 用于访问日志和按 IP 限流，只信任配置的代理和平台请求头，默认不信任任何代理
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		slog.Error("设置可信代理失败，不信任任何代理", "error", err)
+		_ = r.SetTrustedProxies(nil)
+	}
+	r.TrustedPlatform = cfg.Server.TrustedPlatform
+	r.Use(Tracing(cfg.Telemetry.ServiceName), RequestID(), Language(), RequestLogger(), Metrics(), gin.Recovery())
+
+	// 跨域，未配置允许的来源时不启用；需在路由之前注册，预检请求才能被处理
+	if cfg.CORS.Enabled() {
+		r.Use(CORS(&cfg.CORS))
+	}
+
+	// Prometheus 指标，metrics.enabled 为 false 时不暴露
+	if cfg.Metrics.Enabled {
+		r.GET(MetricsPath, MetricsHandler())
 	}
-	gin.SetMode(mode)
 
-	r := gin.Default()
+	// 限流，按路由组使用不同策略；健康检查和指标采集不限流
+	limiter, err := NewRateLimiter(&cfg.RateLimit)
+	if err != nil {
+		slog.Error("初始化限流失败，使用进程内存储", "error", err)
+		limiter = &RateLimiter{store: ratelimit.NewMemoryStore(), cfg: &cfg.RateLimit}
+	}
 
 	// API Routes
 	api := r.Group("/api")
 	{
 		// Health Check Route
 		api.GET("/health", handlers.HealthCheck)
+		api.GET("/health/live", handlers.LivenessCheck)
+		api.GET("/health/ready", handlers.ReadinessCheck)
+
+		// Auth Routes
+		auth := api.Group("/auth", limiter.Group("auth"))
+		{
+			auth.POST("/register", handlers.Register)
+			auth.POST("/login", handlers.Login)
+			auth.GET("/me", RequireAuth(), handlers.GetCurrentUser)
+		}
+
 		// User Profile Routes
-		api.POST("/profile/create", handlers.CreateUserProfile)
-		api.GET("/profile/:id", handlers.GetUserProfile)
+		profile := api.Group("/profile", OptionalAuth(), limiter.Group("default"))
+		{
+			profile.POST("/create", handlers.CreateUserProfile)
+			profile.GET("/:id", handlers.GetUserProfile)
+			profile.PUT("/:id", handlers.UpdateUserProfile)
+			profile.POST("/:id/claim", RequireAuth(), handlers.ClaimUserProfile)
+			profile.GET("/:id/members", handlers.ListProfileMembers)
+			profile.POST("/:id/members", RequireAuth(), handlers.AddProfileMember)
+			profile.DELETE("/:id/members/:userId", RequireAuth(), handlers.RemoveProfileMember)
+			profile.GET("/:id/snapshots", handlers.GetProfileTrend)
+			profile.POST("/:id/snapshots", handlers.CreateProfileSnapshot)
+			profile.DELETE("/:id/snapshots/:snapshotId", handlers.DeleteProfileSnapshot)
+			profile.GET("/:id/recommendations", handlers.GetSavedRecommendations)
+			profile.PUT("/:id/recommendations", handlers.SaveRecommendations)
+		}
+
+		// Counselor Routes
+		counselor := api.Group("/counselor", RequireAuth(), RequireRole(models.RoleCounselor, models.RoleAdmin), limiter.Group("default"))
+		{
+			counselor.GET("/roster", handlers.GetCounselorRoster)
+			counselor.POST("/import", handlers.ImportUserProfiles)
+			counselor.GET("/export", handlers.ExportUserProfiles)
+		}
+
+		// Admin Routes
+		admin := api.Group("/admin", RequireAuth(), RequireRole(models.RoleAdmin), limiter.Group("default"))
+		{
+			admin.PUT("/users/role", handlers.SetUserRole)
+		}
 
 		// Voluntary Routes
-		voluntary := api.Group("/voluntary")
+		voluntary := api.Group("/voluntary", OptionalAuth(), limiter.Group("voluntary"))
 		{
 			voluntary.POST("/universityPriority", handlers.UniversityPriorityVoluntary)
 			voluntary.POST("/majorPriority", handlers.MajorPriorityVoluntary)
@@ -36,18 +100,18 @@ func SetupRouter() *gin.Engine {
 		}
 
 		// Options Routes
-		options := api.Group("/options")
+		options := api.Group("/options", limiter.Group("default"))
 		{
 			options.GET("/provinces", handlers.GetProvinceOptions)
 		}
 
 		// Score Rank Routes
-		scoreRank := api.Group("/rank")
+		scoreRank := api.Group("/rank", limiter.Group("default"))
 		{
 			scoreRank.GET("/getRank", handlers.GetScoreRank)
 			scoreRank.GET("/getScore", handlers.GetRankToScore)
 		}
 	}
 
-	return r
+	return r, limiter
 }
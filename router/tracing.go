@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"strings"
+
+	"gaokao-data-analysis/handlers"
+	"gaokao-data-analysis/logs"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader 请求 trace ID 的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength 客户端传入的 X-Request-ID 最大长度
+const maxRequestIDLength = 128
+
+// Tracing 为每个请求创建 OpenTelemetry span 并继承上游的 traceparent，健康检查和指标采集请求不创建
+func Tracing(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		route := c.FullPath()
+		return !strings.HasPrefix(route, "/api/health") && route != MetricsPath
+	}))
+}
+
+// RequestID 使用客户端传入的 X-Request-ID 作为 trace ID，没有或不合法时生成新的 ID
+// trace ID 写入请求 context 和 gin.Context，并通过响应头返回
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(traceID) {
+			traceID = handlers.GenerateTraceID()
+		}
+
+		c.Request = c.Request.WithContext(logs.WithTraceID(c.Request.Context(), traceID))
+		c.Set(handlers.ContextTraceIDKey, traceID)
+		c.Header(RequestIDHeader, traceID)
+		// 在 Tracing 创建的 span 上记录 X-Request-ID，便于按请求 ID 查找链路
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("http.request.id", traceID))
+		c.Next()
+	}
+}
+
+// validRequestID 只接受长度有限的字母、数字和 - _ . : 组成的 ID，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
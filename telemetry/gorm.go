@@ -0,0 +1,99 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormParentContextKey 保存创建 span 前的 context，结束时恢复，避免同一 Statement 的后续调用挂在已结束的 span 下
+const gormParentContextKey = "telemetry:parent_context"
+
+// GormPlugin 为每次 GORM 数据库操作创建一个 span
+type GormPlugin struct{}
+
+// NewGormPlugin 创建 GORM 链路追踪插件，通过 db.Use 注册
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+// Name 实现 gorm.Plugin
+func (p *GormPlugin) Name() string {
+	return "telemetry"
+}
+
+// Initialize 实现 gorm.Plugin，在各类操作的回调前后创建和结束 span
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, hook := range hooks {
+		if err := hook.before("telemetry:before_"+hook.operation, startGormSpan(hook.operation)); err != nil {
+			return err
+		}
+		if err := hook.after("telemetry:after_"+hook.operation, endGormSpan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startGormSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		parent := db.Statement.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, _ := Tracer().Start(parent, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameKey.String(db.Dialector.Name()),
+				semconv.DBOperationName(operation),
+			),
+		)
+		db.InstanceSet(gormParentContextKey, parent)
+		db.Statement.Context = ctx
+	}
+}
+
+func endGormSpan(db *gorm.DB) {
+	span := trace.SpanFromContext(db.Statement.Context)
+	if !span.IsRecording() {
+		restoreGormContext(db)
+		return
+	}
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		semconv.DBResponseReturnedRows(int(db.Statement.RowsAffected)),
+	)
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 未找到记录是正常的业务结果，不标记为错误
+		err = nil
+	}
+	End(span, &err)
+	restoreGormContext(db)
+}
+
+func restoreGormContext(db *gorm.DB) {
+	if parent, ok := db.InstanceGet(gormParentContextKey); ok {
+		db.Statement.Context = parent.(context.Context)
+	}
+}
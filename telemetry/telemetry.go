@@ -0,0 +1,162 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 链路数据导出方式
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// InstrumentationName 本项目手动创建 span 使用的 tracer 名称
+const InstrumentationName = "gaokao-data-analysis"
+
+// Config 链路追踪配置
+// OTLP 地址、请求头等使用 OpenTelemetry 标准变量，如 OTEL_EXPORTER_OTLP_ENDPOINT，由导出器自行读取
+type Config struct {
+	// Exporter 导出方式: none, stdout, otlp
+	Exporter string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	// ServiceName 上报的服务名
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	// ServiceVersion 上报的服务版本
+	ServiceVersion string `yaml:"service_version" env:"APP_VERSION"`
+	// SampleRatio 根 span 采样比例，0~1；上游已采样的请求始终跟随上游
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+// DefaultConfig 返回默认链路追踪配置，默认不导出
+func DefaultConfig() Config {
+	return Config{
+		Exporter:       ExporterNone,
+		ServiceName:    InstrumentationName,
+		ServiceVersion: "unknown",
+		SampleRatio:    1,
+	}
+}
+
+// Validate 校验链路追踪配置
+func (cfg *Config) Validate() error {
+	var errs []error
+	switch strings.ToLower(cfg.Exporter) {
+	case ExporterNone, ExporterStdout, ExporterOTLP:
+	default:
+		errs = append(errs, fmt.Errorf("OTEL_TRACES_EXPORTER 无效: %s，可选值为 none, stdout, otlp", cfg.Exporter))
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG 无效: %v，应为 0~1 之间的小数", cfg.SampleRatio))
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	mu       sync.Mutex
+	provider *sdktrace.TracerProvider
+)
+
+// Setup 根据配置创建 TracerProvider 并设置为全局实现
+// 导出方式为 none 时只设置传播器，span 不会被记录
+func Setup(ctx context.Context, cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	exporterName := strings.ToLower(cfg.Exporter)
+
+	// 无论是否导出，都透传上游的 traceparent，便于下游服务串联链路
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+	if exporterName == ExporterNone {
+		return nil
+	}
+
+	var option sdktrace.TracerProviderOption
+	switch exporterName {
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return fmt.Errorf("创建 stdout 导出器失败: %w", err)
+		}
+		// 本地调试时同步输出，便于与日志对照
+		option = sdktrace.WithSyncer(exporter)
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return fmt.Errorf("创建 OTLP 导出器失败: %w", err)
+		}
+		option = sdktrace.WithBatcher(exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+	))
+	if err != nil {
+		return fmt.Errorf("创建链路资源信息失败: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		option,
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	mu.Lock()
+	old := provider
+	provider = tp
+	mu.Unlock()
+
+	if old != nil {
+		return old.Shutdown(ctx)
+	}
+	return nil
+}
+
+// Shutdown 导出尚未发送的 span 并关闭 TracerProvider
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if provider == nil {
+		return nil
+	}
+	err := provider.Shutdown(ctx)
+	provider = nil
+	return err
+}
+
+// Tracer 返回本项目使用的 tracer，TracerProvider 晚于调用方初始化时也能生效
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Start 创建子 span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End 结束 span，*errp 不为空时记录错误，用于 defer telemetry.End(span, &err)
+func End(span trace.Span, errp *error) {
+	if errp != nil && *errp != nil && !errors.Is(*errp, context.Canceled) {
+		span.RecordError(*errp)
+		span.SetStatus(codes.Error, (*errp).Error())
+	}
+	span.End()
+}
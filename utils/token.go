@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	tokenSecret     []byte
+	tokenSecretOnce sync.Once
+	tokenTTL        = 72 * time.Hour
+)
+
+// SetTokenConfig 设置签名密钥和令牌有效期，在签发令牌之前调用；secret 为空时使用进程内随机密钥
+func SetTokenConfig(secret string, ttl time.Duration) {
+	tokenSecret = []byte(secret)
+	tokenTTL = ttl
+}
+
+// getTokenSecret 获取签名密钥，未配置 AUTH_JWT_SECRET 时生成进程内随机密钥
+func getTokenSecret() []byte {
+	tokenSecretOnce.Do(func() {
+		if len(tokenSecret) > 0 {
+			return
+		}
+		tokenSecret = make([]byte, 32)
+		if _, err := rand.Read(tokenSecret); err != nil {
+			panic(err)
+		}
+		slog.Warn("未配置 AUTH_JWT_SECRET，使用随机密钥，重启后已签发的令牌将失效")
+	})
+	return tokenSecret
+}
+
+// GenerateToken 为用户签发 HS256 令牌，返回令牌和过期时间
+func GenerateToken(userID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(tokenTTL)
+	claims := jwt.RegisteredClaims{
+		Subject:   userID,
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(getTokenSecret())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ParseToken 校验令牌并返回用户ID
+func ParseToken(tokenString string) (string, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return getTokenSecret(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return "", err
+	}
+	if claims.Subject == "" {
+		return "", errors.New("token subject is empty")
+	}
+	return claims.Subject, nil
+}
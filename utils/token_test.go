@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseToken(t *testing.T) {
+	SetTokenConfig("test-secret", time.Hour)
+
+	valid, _, err := GenerateToken("user-1")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.RegisteredClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+		return token
+	}
+	expiresAt := jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{name: "有效令牌", token: valid, want: "user-1"},
+		{name: "空令牌", token: "", wantErr: true},
+		{name: "篡改签名", token: valid[:len(valid)-2] + "xx", wantErr: true},
+		{
+			name:    "其他密钥签名",
+			token:   sign(jwt.SigningMethodHS256, []byte("other-secret"), jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: expiresAt}),
+			wantErr: true,
+		},
+		{
+			name:    "不允许的签名算法",
+			token:   sign(jwt.SigningMethodHS512, []byte("test-secret"), jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: expiresAt}),
+			wantErr: true,
+		},
+		{
+			name:    "未签名令牌",
+			token:   sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: expiresAt}),
+			wantErr: true,
+		},
+		{
+			name:    "已过期",
+			token:   sign(jwt.SigningMethodHS256, []byte("test-secret"), jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}),
+			wantErr: true,
+		},
+		{
+			name:    "缺少用户ID",
+			token:   sign(jwt.SigningMethodHS256, []byte("test-secret"), jwt.RegisteredClaims{ExpiresAt: expiresAt}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -218,7 +218,7 @@ export function QueryForm({ onSubmit, loading = false }: QueryFormProps) {
       const data = await response.json();
       
       // 解析数据：code 为 0 表示成功
-      if (data.code === 0 && data.data?.provinces) {
+      if (data.code === 200 && data.data?.provinces) {
         setProvincesData(data.data.provinces);
       }
     } catch (error) {